@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// approvedPlanAnnotation on a ManagedKSQL is the hash of the plan which may be applied, it's only needed when the
+// plan has an action listed by requireApprovalFor
+const approvedPlanAnnotation = "mgazza.github.com/approved-plan"
+
+// gatedActions are the actions of plan which managedKSQL requires approval for
+func gatedActions(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, plan *ksqloperatorv1alpha1.Plan) []string {
+	required := map[ksqloperatorv1alpha1.PlanActionType]bool{}
+	for _, action := range managedKSQL.RequireApprovalFor {
+		required[action] = true
+	}
+	var gated []string
+	for _, action := range plan.Actions {
+		switch {
+		case required[action.Action]:
+			gated = append(gated, fmt.Sprintf("%s %s", action.Action, action.Name))
+		case action.MayRecreate && required[ksqloperatorv1alpha1.PlanActionDrop]:
+			// should ksqlDB reject it the stream or table is dropped
+			gated = append(gated, fmt.Sprintf("%s %s (may %s it)", action.Action, action.Name, ksqloperatorv1alpha1.PlanActionDrop))
+		}
+	}
+	return gated
+}
+
+// awaitingApproval plans stmts and reports whether applying them must wait for the plan to be approved, the plan is
+// written to status while it waits
+func (c *Controller) awaitingApproval(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, stmts []ksqlparser.Stmt, props map[string]map[string]string) (bool, error) {
+	plan, err := c.plan(managedKSQL, stmts, props)
+	if err != nil {
+		return false, err
+	}
+	gated := gatedActions(managedKSQL, plan)
+	if len(gated) == 0 {
+		meta.SetStatusCondition(&managedKSQL.Status.Conditions, metav1.Condition{
+			Type:               ksqloperatorv1alpha1.ConditionAwaitingApproval,
+			Status:             metav1.ConditionFalse,
+			Reason:             ksqloperatorv1alpha1.ReasonNothingToApprove,
+			Message:            "the plan has no action which requires approval",
+			ObservedGeneration: managedKSQL.Generation,
+		})
+		return false, nil
+	}
+	if managedKSQL.Annotations[approvedPlanAnnotation] == plan.Hash {
+		meta.SetStatusCondition(&managedKSQL.Status.Conditions, metav1.Condition{
+			Type:               ksqloperatorv1alpha1.ConditionAwaitingApproval,
+			Status:             metav1.ConditionFalse,
+			Reason:             ksqloperatorv1alpha1.ReasonApproved,
+			Message:            fmt.Sprintf("plan %s was approved", plan.Hash),
+			ObservedGeneration: managedKSQL.Generation,
+		})
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("plan %s will %s, set the %s annotation to its hash to apply it",
+		plan.Hash, strings.Join(gated, ", "), approvedPlanAnnotation)
+	condition := meta.FindStatusCondition(managedKSQL.Status.Conditions, ksqloperatorv1alpha1.ConditionAwaitingApproval)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Message != msg {
+		// only once for each plan as we're synced again whenever the status changes
+		c.recorder.Event(managedKSQL, corev1.EventTypeNormal, ksqloperatorv1alpha1.ReasonApprovalRequired, msg)
+	}
+	meta.SetStatusCondition(&managedKSQL.Status.Conditions, metav1.Condition{
+		Type:               ksqloperatorv1alpha1.ConditionAwaitingApproval,
+		Status:             metav1.ConditionTrue,
+		Reason:             ksqloperatorv1alpha1.ReasonApprovalRequired,
+		Message:            msg,
+		ObservedGeneration: managedKSQL.Generation,
+	})
+	managedKSQL.Status.Plan = plan
+	return true, nil
+}
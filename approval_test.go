@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+func TestGatedActions(t *testing.T) {
+	plan := &ksqloperatorv1alpha1.Plan{Actions: []ksqloperatorv1alpha1.PlannedAction{
+		{Action: ksqloperatorv1alpha1.PlanActionCreate, Name: "A"},
+		{Action: ksqloperatorv1alpha1.PlanActionTerminate, Name: "B"},
+		{Action: ksqloperatorv1alpha1.PlanActionDrop, Name: "B"},
+		{Action: ksqloperatorv1alpha1.PlanActionInsert, Name: "C"},
+		{Action: ksqloperatorv1alpha1.PlanActionReplace, Name: "D", MayRecreate: true},
+		{Action: ksqloperatorv1alpha1.PlanActionReplace, Name: "E"},
+	}}
+	tests := []struct {
+		name    string
+		require []ksqloperatorv1alpha1.PlanActionType
+		want    []string
+	}{
+		{name: "nothing required"},
+		{
+			name:    "drop and terminate",
+			require: []ksqloperatorv1alpha1.PlanActionType{ksqloperatorv1alpha1.PlanActionDrop, ksqloperatorv1alpha1.PlanActionTerminate},
+			want:    []string{"Terminate B", "Drop B", "Replace D (may Drop it)"},
+		},
+		{
+			name:    "replace",
+			require: []ksqloperatorv1alpha1.PlanActionType{ksqloperatorv1alpha1.PlanActionReplace},
+			want:    []string{"Replace D", "Replace E"},
+		},
+		{
+			name:    "create",
+			require: []ksqloperatorv1alpha1.PlanActionType{ksqloperatorv1alpha1.PlanActionCreate},
+			want:    []string{"Create A"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{RequireApprovalFor: tt.require}
+			got := gatedActions(managedKSQL, plan)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("gatedActions() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// ksqlfmt formats ksql files and the statement blocks of ManagedKSQL manifests.
+//
+// Usage:
+//
+//	ksqlfmt [flags] [path ...]
+//
+// Files ending in .yaml or .yml are treated as kubernetes manifests, only the block scalar `statement: |` of
+// ManagedKSQL documents is rewritten. Any other file is treated as ksql. With no paths ksql is read from stdin.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ksql_operator/ksqlparser"
+)
+
+var (
+	write         bool
+	list          bool
+	indent        int
+	lower         bool
+	compact       bool
+	noAlignWith   bool
+	stripComments bool
+)
+
+var (
+	documentSeparator = regexp.MustCompile(`(?m)^---`)
+	managedKSQLKind   = regexp.MustCompile(`(?m)^kind:\s*ManagedKSQL\s*$`)
+	statementBlock    = regexp.MustCompile(`^(\s*)statement:\s*[|>][-+]?\s*$`)
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: ksqlfmt [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	opts := ksqlparser.FormatOptions{
+		IndentWidth:          indent,
+		KeywordCase:          ksqlparser.KeywordCaseUpper,
+		OneSelectItemPerLine: !compact,
+		AlignWith:            !noAlignWith,
+		KeepComments:         !stripComments,
+	}
+	if lower {
+		opts.KeywordCase = ksqlparser.KeywordCaseLower
+	}
+
+	if flag.NArg() == 0 {
+		in, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			exit(err)
+		}
+		out, err := formatKSQL(string(in), opts)
+		if err != nil {
+			exit(err)
+		}
+		fmt.Print(out)
+		return
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		if err := processFile(path, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func processFile(path string, opts ksqlparser.FormatOptions) error {
+	in, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var out string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		out, err = formatManifest(string(in), opts)
+	default:
+		out, err = formatKSQL(string(in), opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(in, []byte(out)) {
+		return nil
+	}
+	if list {
+		fmt.Println(path)
+	}
+	if write {
+		return ioutil.WriteFile(path, []byte(out), 0644)
+	}
+	if !list {
+		fmt.Print(out)
+	}
+	return nil
+}
+
+func formatKSQL(ksql string, opts ksqlparser.FormatOptions) (string, error) {
+	stmts, err := ksqlparser.Parse(ksql)
+	if err != nil {
+		return "", err
+	}
+	return ksqlparser.Format(stmts, opts), nil
+}
+
+// formatManifest rewrites the statement block of each ManagedKSQL document leaving the rest of the text untouched
+func formatManifest(manifest string, opts ksqlparser.FormatOptions) (string, error) {
+	docs := documentSeparator.Split(manifest, -1)
+	separators := documentSeparator.FindAllString(manifest, -1)
+
+	var sb strings.Builder
+	for i, doc := range docs {
+		if i > 0 {
+			sb.WriteString(separators[i-1])
+		}
+		if !managedKSQLKind.MatchString(doc) {
+			sb.WriteString(doc)
+			continue
+		}
+		formatted, err := formatStatementBlock(doc, opts)
+		if err != nil {
+			return "", fmt.Errorf("document %d: %v", i, err)
+		}
+		sb.WriteString(formatted)
+	}
+	return sb.String(), nil
+}
+
+func formatStatementBlock(doc string, opts ksqlparser.FormatOptions) (string, error) {
+	lines := strings.Split(doc, "\n")
+	for i, line := range lines {
+		m := statementBlock.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		keyIndent := len(m[1])
+
+		// the block runs until the first non blank line which is not indented further than the key
+		end := i + 1
+		blockIndent := -1
+		for ; end < len(lines); end++ {
+			l := lines[end]
+			if strings.TrimSpace(l) == "" {
+				continue
+			}
+			n := len(l) - len(strings.TrimLeft(l, " "))
+			if n <= keyIndent {
+				break
+			}
+			if blockIndent < 0 {
+				blockIndent = n
+			}
+		}
+		// keep any trailing blank lines outside of the block
+		for end > i+1 && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		if blockIndent < 0 {
+			return doc, nil
+		}
+
+		var ksql []string
+		for _, l := range lines[i+1 : end] {
+			if len(l) >= blockIndent {
+				l = l[blockIndent:]
+			}
+			ksql = append(ksql, l)
+		}
+		formatted, err := formatKSQL(strings.Join(ksql, "\n"), opts)
+		if err != nil {
+			return "", err
+		}
+
+		var block []string
+		for _, l := range strings.Split(strings.TrimRight(formatted, "\n"), "\n") {
+			if l == "" {
+				block = append(block, l)
+				continue
+			}
+			block = append(block, strings.Repeat(" ", blockIndent)+l)
+		}
+
+		result := append([]string{}, lines[:i+1]...)
+		result = append(result, block...)
+		result = append(result, lines[end:]...)
+		return strings.Join(result, "\n"), nil
+	}
+	return doc, nil
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+func init() {
+	flag.BoolVar(&write, "w", false, "Write the result back to the source file instead of stdout.")
+	flag.BoolVar(&list, "l", false, "List the files whose formatting differs from ksqlfmt's.")
+	flag.IntVar(&indent, "indent", ksqlparser.DefaultFormatOptions.IndentWidth, "The number of spaces to indent by.")
+	flag.BoolVar(&lower, "lower", false, "Write keywords in lower case.")
+	flag.BoolVar(&compact, "compact", false, "Write select items on a single line.")
+	flag.BoolVar(&noAlignWith, "no-align-with", false, "Write WITH properties on a single line.")
+	flag.BoolVar(&stripComments, "strip-comments", false, "Remove comments.")
+}
@@ -0,0 +1,136 @@
+// ksqlgraph renders the flow of data between ksql statements as Graphviz DOT, Mermaid or JSON, or the column
+// lineage of the statements as OpenLineage job events.
+//
+// Usage:
+//
+//	ksqlgraph [flags] [path ...]
+//
+// Files ending in .yaml or .yml are treated as kubernetes manifests and the statements of every ManagedKSQL
+// document are used. Any other file is treated as ksql. With no paths ksql is read from stdin.
+// The statements of every path are rendered as a single graph.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"ksql_operator/ksqlparser"
+	"ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+	"ksql_operator/pkg/generated/clientset/versioned/scheme"
+)
+
+var (
+	format    string
+	namespace string
+)
+
+var documentSeparator = regexp.MustCompile(`(?m)^---`)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: ksqlgraph [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var sqls []string
+	if flag.NArg() == 0 {
+		in, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			exit(err)
+		}
+		sqls = append(sqls, string(in))
+	}
+	for _, path := range flag.Args() {
+		s, err := readStatements(path)
+		if err != nil {
+			exit(fmt.Errorf("%s: %v", path, err))
+		}
+		sqls = append(sqls, s...)
+	}
+
+	stmts, err := ksqlparser.Parse(sqls...)
+	if err != nil {
+		exit(err)
+	}
+	g := ksqlparser.NewGraph(stmts)
+
+	switch strings.ToLower(format) {
+	case "dot":
+		fmt.Print(g.DOT())
+	case "mermaid":
+		fmt.Print(g.Mermaid())
+	case "json":
+		b, err := g.JSON()
+		if err != nil {
+			exit(err)
+		}
+		fmt.Println(string(b))
+	case "openlineage":
+		opts := ksqlparser.DefaultOpenLineageOptions
+		if namespace != "" {
+			opts.Namespace = namespace
+		}
+		b, err := ksqlparser.OpenLineage(stmts, opts)
+		if err != nil {
+			exit(err)
+		}
+		fmt.Println(string(b))
+	default:
+		exit(fmt.Errorf("unknown format %q", format))
+	}
+}
+
+// readStatements returns the ksql in path
+func readStatements(path string) ([]string, error) {
+	in, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return manifestStatements(in)
+	default:
+		return []string{string(in)}, nil
+	}
+}
+
+// manifestStatements returns the statements of every ManagedKSQL document in manifest
+func manifestStatements(manifest []byte) ([]string, error) {
+	var result []string
+	decoder := scheme.Codecs.UniversalDeserializer()
+	for i, doc := range documentSeparator.Split(string(manifest), -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		obj, _, err := decoder.Decode([]byte(doc), nil, nil)
+		if err != nil {
+			if runtime.IsNotRegisteredError(err) || runtime.IsMissingKind(err) {
+				// not one of ours
+				continue
+			}
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		if managedKSQL, ok := obj.(*v1alpha1.ManagedKSQL); ok {
+			result = append(result, managedKSQL.Statement)
+		}
+	}
+	return result, nil
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+func init() {
+	flag.StringVar(&format, "format", "dot", "The output format, one of dot, mermaid, json or openlineage.")
+	flag.StringVar(&namespace, "namespace", "", "The OpenLineage namespace of the jobs and datasets.")
+}
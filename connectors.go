@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"ksql_operator/ksqlclient"
+	"ksql_operator/ksqlclient/swagger"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// connectorErrorType is the @type of a response to a connector statement which failed
+const connectorErrorType = "error_entity"
+
+// describeConnector describes the connector n, it returns nil if there is no such connector
+func (c *Controller) describeConnector(n string) (*swagger.ConnectorDescriptionResponseItem, error) {
+	resp, err := c.ksqlClient.Execute(context.Background(), fmt.Sprintf("DESCRIBE CONNECTOR %s;", n),
+		&[]swagger.ConnectorDescriptionResponseItem{})
+	if err != nil {
+		return nil, err
+	}
+	switch resp := resp.(type) {
+	case *swagger.ModelError:
+		if resp.ErrorCode == ksqlclient.ErrCodeNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error response from ksql: (%0f) %s\n%s",
+			resp.ErrorCode, resp.Message, strings.Join(resp.StackTrace, "\n"))
+	case *[]swagger.ConnectorDescriptionResponseItem:
+		if len(*resp) != 1 {
+			// this is likely to be unrecoverable
+			return nil, fmt.Errorf("expected only one response from ksql but got %d, \n %v", len(*resp), resp)
+		}
+		if (*resp)[0].Type_ == connectorErrorType {
+			// connect doesn't know of it
+			klog.V(5).Infof("describing connector %s: %s", n, (*resp)[0].ErrorMessage)
+			return nil, nil
+		}
+		return &(*resp)[0], nil
+	}
+	return nil, fmt.Errorf("unexpected result type %T", resp)
+}
+
+// executeConnectorStmt runs a CREATE or DROP CONNECTOR statement
+func (c *Controller) executeConnectorStmt(ksql string) (*swagger.ConnectorResponseItem, error) {
+	resp, err := c.ksqlClient.Execute(context.Background(), ksql, &[]swagger.ConnectorResponseItem{})
+	if err != nil {
+		return nil, err
+	}
+	switch resp := resp.(type) {
+	case *swagger.ModelError:
+		return nil, fmt.Errorf("error response from ksql: (%0f) %s\n%s",
+			resp.ErrorCode, resp.Message, strings.Join(resp.StackTrace, "\n"))
+	case *[]swagger.ConnectorResponseItem:
+		if len(*resp) != 1 {
+			// this is likely to be unrecoverable
+			return nil, fmt.Errorf("expected only one response from ksql but got %d, \n %v", len(*resp), resp)
+		}
+		if (*resp)[0].Type_ == connectorErrorType {
+			return nil, fmt.Errorf("error response from ksql: %s", (*resp)[0].ErrorMessage)
+		}
+		return &(*resp)[0], nil
+	}
+	return nil, fmt.Errorf("unexpected result type %T", resp)
+}
+
+// processConnector creates the connector declared by stmt and records its state.
+// ksqlDB doesn't describe the config of a connector so drift is found by comparing the class described with the one
+// declared and the statement with the one last applied, either differing drops and creates the connector again.
+func (c *Controller) processConnector(stmt ksqlparser.Stmt, ksql, hash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+	n := stmt.GetName()
+	desc, err := c.describeConnector(n)
+	if err != nil {
+		return err
+	}
+	class := strings.Trim(ksqlparser.Properties(stmt)[ksqlparser.ConnectorPropertyClass], "'")
+	if desc != nil {
+		if commandStatus.QuerySha == hash && sameConnectorClass(desc.ConnectorClass, class) {
+			commandStatus.Connector = connectorStatus(desc)
+			return nil
+		}
+		klog.V(4).Infof("connector %s differs from its statement, recreating", n)
+		if err := c.DropConnector(n); err != nil {
+			return err
+		}
+	}
+
+	klog.V(5).Infof("creating connector %s", n)
+	resp, err := c.executeConnectorStmt(ksql)
+	if err != nil {
+		commandStatus.Status = ksqloperatorv1alpha1.StatusError
+		return err
+	}
+	commandStatus.Status = ksqloperatorv1alpha1.StatusSuccess
+	commandStatus.StatusSha = StmtHasher(resp.StatementText)
+	commandStatus.QuerySha = hash
+	// the state is known once it's next described
+	commandStatus.Connector = &ksqloperatorv1alpha1.ConnectorStatus{Class: class}
+	return nil
+}
+
+// processDropConnector runs a DROP CONNECTOR once for each version of the statement
+func (c *Controller) processDropConnector(ksql, hash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+	if commandStatus.QuerySha == hash {
+		return nil
+	}
+	resp, err := c.executeConnectorStmt(ksql)
+	if err != nil {
+		commandStatus.Status = ksqloperatorv1alpha1.StatusError
+		return err
+	}
+	commandStatus.Status = ksqloperatorv1alpha1.StatusSuccess
+	commandStatus.StatusSha = StmtHasher(resp.StatementText)
+	commandStatus.QuerySha = hash
+	return nil
+}
+
+// DropConnector drops the connector n if it exists
+func (c *Controller) DropConnector(n string) error {
+	if _, err := c.executeConnectorStmt(fmt.Sprintf("DROP CONNECTOR IF EXISTS %s;", n)); err != nil {
+		return fmt.Errorf("error dropping connector %s: %v", n, err)
+	}
+	return nil
+}
+
+// sameConnectorClass reports whether the class described by ksqlDB is the class declared, which may be an alias of
+// the simple name of the class with or without the Connector suffix
+func sameConnectorClass(described, declared string) bool {
+	if declared == "" || described == declared {
+		return true
+	}
+	return strings.HasSuffix(described, "."+declared) || strings.HasSuffix(described, "."+declared+"Connector")
+}
+
+// connectorStatus is the state of the connector described by desc
+func connectorStatus(desc *swagger.ConnectorDescriptionResponseItem) *ksqloperatorv1alpha1.ConnectorStatus {
+	result := &ksqloperatorv1alpha1.ConnectorStatus{Class: desc.ConnectorClass}
+	if desc.Status == nil {
+		return result
+	}
+	if desc.Status.Connector != nil {
+		result.State = desc.Status.Connector.State
+		result.WorkerID = desc.Status.Connector.WorkerId
+		result.Trace = desc.Status.Connector.Trace
+	}
+	for _, task := range desc.Status.Tasks {
+		result.Tasks = append(result.Tasks, ksqloperatorv1alpha1.ConnectorTaskStatus{
+			ID:       int(task.Id),
+			State:    task.State,
+			WorkerID: task.WorkerId,
+			Trace:    task.Trace,
+		})
+	}
+	return result
+}
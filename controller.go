@@ -25,6 +25,7 @@ import (
 	"ksql_operator/ksqlclient"
 	"ksql_operator/ksqlclient/swagger"
 	"ksql_operator/ksqlparser"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -36,10 +37,13 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -55,6 +59,12 @@ import (
 
 const controllerAgentName = "ksql-manager"
 
+// upstreamRequeueDelay is how long to wait before checking upstream ManagedKSQLs again should their update be missed
+const upstreamRequeueDelay = time.Minute
+
+// graphConfigMapSuffix is appended to the name of a ManagedKSQL to name the ConfigMap holding its graph
+const graphConfigMapSuffix = "-graph"
+
 type KSQLClient interface {
 	Describe(ctx context.Context, name string) (interface{}, error)
 	Explain(ctx context.Context, name string) (interface{}, error)
@@ -69,6 +79,20 @@ var DefaultHasher = func(s string) string {
 	return base64.URLEncoding.EncodeToString(hasher.Sum(nil))
 }
 
+// StmtHasher hashes the canonical form of a ksql statement so that the formatting differences between what we issue
+// and what ksqlDB reports back are not seen as drift
+var StmtHasher = func(s string) string {
+	return DefaultHasher(ksqlparser.Normalise(s))
+}
+
+// upgradeLegacySha replaces a sha which was taken over the raw text of s with the canonical one
+// this stops resources applied before statements were normalised from being dropped and recreated
+func upgradeLegacySha(sha *string, s string) {
+	if *sha != "" && *sha == DefaultHasher(s) {
+		*sha = StmtHasher(s)
+	}
+}
+
 // Controller is the controller implementation for KSQLDefinition resources
 type Controller struct {
 	// clientSet is a clientset for our own API group
@@ -79,6 +103,18 @@ type Controller struct {
 	managedKSQLLister listers.ManagedKSQLLister
 	ManagedKSQLSynced cache.InformerSynced
 
+	ksqlPolicyLister listers.KSQLPolicyLister
+	KSQLPolicySynced cache.InformerSynced
+
+	configMapLister corelisters.ConfigMapLister
+	ConfigMapSynced cache.InformerSynced
+
+	secretLister corelisters.SecretLister
+	SecretSynced cache.InformerSynced
+
+	namespaceLister corelisters.NamespaceLister
+	NamespaceSynced cache.InformerSynced
+
 	// workqueue is a rate limited work queue. This is used to queue work to be
 	// processed instead of performing it as soon as a change happens. This
 	// means we can ensure we only process a fixed amount of resources at a
@@ -94,6 +130,16 @@ type Controller struct {
 
 	//cache is a thread safe cache for storing previously seen resources
 	cache *safeCache
+
+	// index records which ManagedKSQL declares each source across the cluster
+	index *sourceIndex
+
+	// publishGraph writes the dependency graph of each resource to a ConfigMap
+	publishGraph bool
+
+	// defaultNamingPolicy is the template used to name streams and tables in namespaces without a naming policy
+	// annotation, when empty names are used as written
+	defaultNamingPolicy string
 }
 
 // NewController returns a new sample controller
@@ -101,6 +147,10 @@ func NewController(
 	kubeClientSet kubernetes.Interface,
 	clientSet clientset.Interface,
 	ksqlDefinitionInformer informers.ManagedKSQLInformer,
+	ksqlPolicyInformer informers.KSQLPolicyInformer,
+	configMapInformer coreinformers.ConfigMapInformer,
+	secretInformer coreinformers.SecretInformer,
+	namespaceInformer coreinformers.NamespaceInformer,
 	ksqlClient KSQLClient,
 ) *Controller {
 
@@ -119,6 +169,14 @@ func NewController(
 		clientSet:         clientSet,
 		managedKSQLLister: ksqlDefinitionInformer.Lister(),
 		ManagedKSQLSynced: ksqlDefinitionInformer.Informer().HasSynced,
+		ksqlPolicyLister:  ksqlPolicyInformer.Lister(),
+		KSQLPolicySynced:  ksqlPolicyInformer.Informer().HasSynced,
+		configMapLister:   configMapInformer.Lister(),
+		ConfigMapSynced:   configMapInformer.Informer().HasSynced,
+		secretLister:      secretInformer.Lister(),
+		SecretSynced:      secretInformer.Informer().HasSynced,
+		namespaceLister:   namespaceInformer.Lister(),
+		NamespaceSynced:   namespaceInformer.Informer().HasSynced,
 		workqueue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "ManagedKSQLs"),
 		recorder:          recorder,
 		ksqlClient:        ksqlClient,
@@ -128,21 +186,100 @@ func NewController(
 	// Set up an event handler for when KSQLDefinition resources change
 
 	controller.cache = NewSafeCache()
+	controller.index = newSourceIndex()
 
 	ksqlDefinitionInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
-			AddFunc: controller.enqueueManagedKSQL,
+			AddFunc: func(obj interface{}) {
+				controller.enqueueManagedKSQL(obj)
+				// anything reading from a recreated resource needs to check it again
+				controller.enqueueDependants(obj)
+			},
 			UpdateFunc: func(old, new interface{}) {
 				// diff and only queue on changes
 				if diff := deep.Equal(old, new); diff != nil {
 					controller.enqueueManagedKSQL(new)
 				}
+				oldStatus := old.(*ksqloperatorv1alpha1.ManagedKSQL).Status
+				newStatus := new.(*ksqloperatorv1alpha1.ManagedKSQL).Status
+				if oldStatus.Applied != newStatus.Applied || deep.Equal(oldStatus.ItemStatus, newStatus.ItemStatus) != nil {
+					// the upstream has been applied or its streams and tables recreated
+					controller.enqueueDependants(new)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				controller.enqueueManagedKSQL(obj)
+				controller.enqueueDependants(obj)
+			},
+		})
+	// every resource is checked again when the policies change
+	ksqlPolicyInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				controller.enqueueAll()
+			},
+			UpdateFunc: func(old, new interface{}) {
+				if diff := deep.Equal(old, new); diff != nil {
+					controller.enqueueAll()
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				controller.enqueueAll()
+			},
+		})
+	// resources taking statements or variables from a ConfigMap are checked again when it changes
+	configMapInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: controller.enqueueReferencing,
+			UpdateFunc: func(old, new interface{}) {
+				if old.(*corev1.ConfigMap).ResourceVersion != new.(*corev1.ConfigMap).ResourceVersion {
+					controller.enqueueReferencing(new)
+				}
+			},
+			DeleteFunc: controller.enqueueReferencing,
+		})
+	// and so are those taking them from a Secret
+	secretInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: controller.enqueueReferencing,
+			UpdateFunc: func(old, new interface{}) {
+				if old.(*corev1.Secret).ResourceVersion != new.(*corev1.Secret).ResourceVersion {
+					controller.enqueueReferencing(new)
+				}
+			},
+			DeleteFunc: controller.enqueueReferencing,
+		})
+	// the naming policy of a namespace may be changed by annotating it
+	namespaceInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, new interface{}) {
+				oldAnnotations := old.(*corev1.Namespace).Annotations
+				newAnnotations := new.(*corev1.Namespace).Annotations
+				if oldAnnotations[namingPolicyAnnotation] != newAnnotations[namingPolicyAnnotation] {
+					controller.enqueueNamespace(new)
+				}
 			},
-			DeleteFunc: controller.enqueueManagedKSQL,
 		})
 	return controller
 }
 
+// conflicts are the sources declared by stmts, and the connectors they drop, which are owned by another ManagedKSQL
+// keyed by name with the key of their owner
+func (c *Controller) conflicts(key string, stmts []ksqlparser.Stmt) map[string]string {
+	conflicts := c.index.Conflicts(key)
+	for _, stmt := range stmts {
+		name, ok := ksqlparser.DroppedConnector(stmt)
+		if !ok {
+			continue
+		}
+		// the owner would only create it again
+		if owner, ok := c.index.Owner(name); ok && owner != key {
+			conflicts[strings.ToUpper(name)] = owner
+		}
+	}
+	return conflicts
+}
+
 // GetNewerByResourceVersion takes a and b which are k8s resources and returns the one with the latest version number
 func (c *Controller) GetNewerByResourceVersion(a, b interface{}) (interface{}, error) {
 	metaA, err := meta.Accessor(a)
@@ -183,10 +320,15 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 
 	// Wait for the caches to be synced before starting workers
 	klog.Info("Waiting for informer caches to sync")
-	if ok := cache.WaitForCacheSync(stopCh, c.ManagedKSQLSynced); !ok {
+	if ok := c.waitForCacheSync(stopCh); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
+	// claim the sources of everything up front so ownership doesn't depend on the order resources are synced in
+	if err := c.seedIndex(); err != nil {
+		return err
+	}
+
 	klog.Info("Starting workers")
 	// Launch two workers to process KSQLDefinition resources
 	for i := 0; i < threadiness; i++ {
@@ -200,6 +342,12 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	return nil
 }
 
+// waitForCacheSync waits for the caches of the listers to be synced, it returns false when stopCh is closed first
+func (c *Controller) waitForCacheSync(stopCh <-chan struct{}) bool {
+	return cache.WaitForCacheSync(stopCh, c.ManagedKSQLSynced, c.KSQLPolicySynced, c.ConfigMapSynced, c.SecretSynced,
+		c.NamespaceSynced)
+}
+
 // runWorker is a long-running function that will continually call the
 // processNextWorkItem function in order to read and process a message on the
 // workqueue.
@@ -273,7 +421,16 @@ func (c *Controller) getKeyForResource(obj interface{}) (string, error) {
 
 type cacheItem struct {
 	Resource *ksqloperatorv1alpha1.ManagedKSQL
-	Stmts    []ksqlparser.Stmt
+	// Statement is the statement of Resource once its variables were substituted
+	Statement string
+	Stmts     []ksqlparser.Stmt
+	Names     map[string]string
+	// Props are the streams properties of each statement keyed by its name
+	Props map[string]map[string]string
+	// Versions is the versionsKey of Resource when Stmts were versioned
+	Versions string
+	// NamingPolicy is the naming policy of the namespace of Resource when Stmts were renamed
+	NamingPolicy string
 }
 
 // syncHandler compares the actual state with the desired, and attempts to
@@ -313,23 +470,49 @@ func (c *Controller) syncHandler(key string) error {
 					}
 				}
 			}
+			// only drop what we own, anything else belongs to another resource
+			owned := map[string]bool{}
+			for _, source := range c.index.Owned(key) {
+				owned[source] = true
+			}
 			for _, stmt := range ci.Stmts {
 				switch stmt.GetActionType() {
 				case ksqlparser.StmtTypeCreate:
 					fallthrough
 				case ksqlparser.StmtTypeCreateOrReplace:
+					if !owned[strings.ToUpper(baseName(ci.Resource, stmt.GetName()))] {
+						klog.V(5).Infof("not dropping %s which is owned by another resource", stmt.GetName())
+						continue
+					}
 					createStmt := stmt.(ksqlparser.CreateStmt)
 					t := createStmt.GetObjectType()
-					klog.V(5).Infof("dropping %s %s", t, stmt.GetName())
-					err := c.DropTableStreamChain(string(t), stmt.GetName())
-					if err != nil {
-						klog.Errorf("error dropping %s %s: %v", t, stmt.GetName(), err)
+					// a migrated stream or table is on the server under its versions
+					for _, n := range versionNames(ci.Resource, stmt.GetName()) {
+						klog.V(5).Infof("dropping %s %s", t, n)
+						var err error
+						switch t {
+						case ksqlparser.CreateObjectTypeType:
+							err = c.DropType(n)
+						case ksqlparser.CreateObjectTypeConnector:
+							err = c.DropConnector(n)
+						default:
+							err = c.DropTableStreamChain(string(t), n)
+						}
+						if err != nil {
+							klog.Errorf("error dropping %s %s: %v", t, n, err)
+						}
 					}
 				}
 			}
 
 			//finally
 			c.cache.Delete(key)
+			rivals := c.index.Rivals(key)
+			c.index.Delete(key)
+			// another resource may now own what we declared
+			for _, rival := range rivals {
+				c.workqueue.Add(rival)
+			}
 
 			return nil
 		}
@@ -339,9 +522,34 @@ func (c *Controller) syncHandler(key string) error {
 		// temporary network failure, or any other transient reason.
 		return err
 	}
+	// the lister's copy is shared with the informer's cache so it mustn't be changed
+	managedKSQL = managedKSQL.DeepCopy()
+
+	// the statements and values of variables can change without the resource changing
+	statement, err := c.statement(managedKSQL)
+	if err != nil {
+		c.setConditionError(managedKSQL, ksqloperatorv1alpha1.ConditionParsed, ksqloperatorv1alpha1.ReasonSourceError, err)
+		// a ConfigMap or Secret may yet be created
+		return err
+	}
+	statement, err = c.substituteVariables(managedKSQL, statement)
+	if err != nil {
+		c.setConditionError(managedKSQL, ksqloperatorv1alpha1.ConditionParsed, ksqloperatorv1alpha1.ReasonVariableError, err)
+		// a ConfigMap or Secret may yet be created
+		return err
+	}
+	// so does the naming policy of its namespace
+	policy, err := c.namingPolicySource(managedKSQL.Namespace)
+	if err != nil {
+		return err
+	}
 
 	var stmts []ksqlparser.Stmt
-	// pull or build stmts out of the cache by using closure
+	var names map[string]string
+	var props map[string]map[string]string
+	// stale is true when the statements were parsed again and still have to be put in dependency order
+	var stale bool
+	// pull the stmts out of the cache or parse and index them by using closure
 	err = c.cache.Sync(key, func(obj interface{}) (interface{}, error) {
 		ci := &cacheItem{}
 		if obj != nil {
@@ -351,64 +559,278 @@ func (c *Controller) syncHandler(key string) error {
 			}
 			ci = c
 		}
-		if ci.Resource == nil || ci.Resource.ResourceVersion < managedKSQL.ResourceVersion {
+		if ci.Resource == nil || ci.Resource.ResourceVersion < managedKSQL.ResourceVersion || ci.Statement != statement ||
+			ci.Versions != versionsKey(managedKSQL) || ci.NamingPolicy != policy {
 			klog.V(4).Info("parsing ksql")
 			// lets parse the statement in this resource
-			stmts, err = ksqlparser.Parse(managedKSQL.Statement)
+			var err error
+			stmts, names, props, err = c.parseStatement(managedKSQL)
 			if err != nil {
 				return nil, err
-
 			}
-			klog.V(4).Info("building dependency graph")
-			//sort statements in a safe dependency order
-			lenin := len(stmts)
-			stmts = ksqlparser.BuildDependencyGraph(stmts)
-			if lenin != len(stmts) {
-				// some kind of dependency loop
-				err := fmt.Errorf("error resolving dependencies - possible dependency loop")
-				return nil, err
+			// whoever we were contending with may now own what we declared
+			for _, rival := range c.index.Rivals(key) {
+				c.workqueue.Add(rival)
 			}
-			ci.Resource = managedKSQL
-			ci.Stmts = stmts
-		} else {
-			stmts = ci.Stmts
+			c.index.Set(key, managedKSQL, stmts)
+			stale = true
+			// what's cached is left alone until the graph is built
+			return obj, nil
 		}
+		stmts = ci.Stmts
+		names = ci.Names
+		props = ci.Props
+		// what's dropped when the resource is deleted comes from its latest status
+		ci.Resource = managedKSQL
 		return ci, nil
 	})
+	if err == nil && stale {
+		// whether sources exist is asked of the ksql server so the cache isn't held while the graph is built
+		klog.V(4).Info("building dependency graph")
+		//sort statements in a safe dependency order
+		stmts, err = ksqlparser.BuildDependencyGraph(stmts, func(name string) (bool, error) {
+			// sources of other resources will be waited on
+			if _, ok := c.index.Owner(name); ok {
+				return true, nil
+			}
+			return c.sourceExists(name)
+		})
+	}
+	if err == nil && stale {
+		// migrated streams and tables are renamed once their sources have been resolved by the names as written
+		names, props = versionStatements(managedKSQL, stmts, names, props)
+		err = c.cache.Sync(key, func(interface{}) (interface{}, error) {
+			return &cacheItem{
+				Resource:     managedKSQL,
+				Statement:    statement,
+				Stmts:        stmts,
+				Names:        names,
+				Props:        props,
+				Versions:     versionsKey(managedKSQL),
+				NamingPolicy: policy,
+			}, nil
+		})
+	}
 	if err != nil {
+		switch e := err.(type) {
+		case ksqlparser.ParseErrors:
+			// there's no point in retrying until the statement has been changed
+			c.setConditionError(managedKSQL, ksqloperatorv1alpha1.ConditionParsed, ksqloperatorv1alpha1.ReasonParseError, e)
+			return nil
+		case *ksqlparser.DependencyError:
+			c.setConditionError(managedKSQL, ksqloperatorv1alpha1.ConditionDependenciesResolved,
+				ksqloperatorv1alpha1.ReasonDependencyError, e)
+			if len(e.Undefined) > 0 {
+				// the missing sources may yet be created
+				return e
+			}
+			return nil
+		}
 		return fmt.Errorf("error pulling key from cache: %v", err)
 	}
 
 	if managedKSQL.Status.ItemStatus == nil {
 		managedKSQL.Status.ItemStatus = map[string]ksqloperatorv1alpha1.CommandStatus{}
 	}
+	managedKSQL.Status.Names = names
+	managedKSQL.Status.Statements = len(stmts)
+	meta.SetStatusCondition(&managedKSQL.Status.Conditions, metav1.Condition{
+		Type:               ksqloperatorv1alpha1.ConditionParsed,
+		Status:             metav1.ConditionTrue,
+		Reason:             ksqloperatorv1alpha1.ReasonParsed,
+		Message:            fmt.Sprintf("parsed %d statements", len(stmts)),
+		ObservedGeneration: managedKSQL.Generation,
+	})
+	meta.SetStatusCondition(&managedKSQL.Status.Conditions, metav1.Condition{
+		Type:               ksqloperatorv1alpha1.ConditionDependenciesResolved,
+		Status:             metav1.ConditionTrue,
+		Reason:             ksqloperatorv1alpha1.ReasonResolved,
+		Message:            "statements are in dependency order",
+		ObservedGeneration: managedKSQL.Generation,
+	})
+
+	policies, err := c.ksqlPolicyLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing policies: %v", err)
+	}
+	if violations := policyViolations(policies, managedKSQL.Namespace, stmts); len(violations) > 0 {
+		// we'll be queued again when the statement or the policies change
+		c.setConditionError(managedKSQL, ksqloperatorv1alpha1.ConditionPolicyCompliant,
+			ksqloperatorv1alpha1.ReasonPolicyViolation, fmt.Errorf("%s", strings.Join(violations, ", ")))
+		return nil
+	}
+	meta.SetStatusCondition(&managedKSQL.Status.Conditions, metav1.Condition{
+		Type:               ksqloperatorv1alpha1.ConditionPolicyCompliant,
+		Status:             metav1.ConditionTrue,
+		Reason:             ksqloperatorv1alpha1.ReasonCompliant,
+		Message:            "meets every KSQLPolicy",
+		ObservedGeneration: managedKSQL.Generation,
+	})
+
+	if conflicts := c.conflicts(key, stmts); len(conflicts) > 0 {
+		var msgs []string
+		for source, owner := range conflicts {
+			msgs = append(msgs, fmt.Sprintf("%s is owned by %s", source, owner))
+		}
+		sort.Strings(msgs)
+		// leave everything alone until the owner gives up its claim
+		c.setConditionError(managedKSQL, ksqloperatorv1alpha1.ConditionClaimed, ksqloperatorv1alpha1.ReasonOwnershipConflict,
+			fmt.Errorf("%s", strings.Join(msgs, ", ")))
+		return nil
+	}
+	meta.SetStatusCondition(&managedKSQL.Status.Conditions, metav1.Condition{
+		Type:               ksqloperatorv1alpha1.ConditionClaimed,
+		Status:             metav1.ConditionTrue,
+		Reason:             ksqloperatorv1alpha1.ReasonClaimed,
+		Message:            "owns every stream and table it declares",
+		ObservedGeneration: managedKSQL.Generation,
+	})
+	managedKSQL.Status.Owned = c.index.Owned(key)
+
+	if waiting := c.waitingOn(key); len(waiting) > 0 {
+		cp := managedKSQL.DeepCopy()
+		cp.Status.Applied = ksqloperatorv1alpha1.StatusPending
+		meta.SetStatusCondition(&cp.Status.Conditions, metav1.Condition{
+			Type:               ksqloperatorv1alpha1.ConditionUpstreamReady,
+			Status:             metav1.ConditionFalse,
+			Reason:             ksqloperatorv1alpha1.ReasonWaitingOnUpstream,
+			Message:            fmt.Sprintf("waiting on %s", strings.Join(waiting, ", ")),
+			ObservedGeneration: cp.Generation,
+		})
+		if err := c.updateManagedKSQLStatus(cp); err != nil {
+			return err
+		}
+		// we'll be queued when the upstream is updated but check again later in case that's missed
+		c.workqueue.AddAfter(key, upstreamRequeueDelay)
+		return nil
+	}
+	meta.SetStatusCondition(&managedKSQL.Status.Conditions, metav1.Condition{
+		Type:               ksqloperatorv1alpha1.ConditionUpstreamReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             ksqloperatorv1alpha1.ReasonUpstreamReady,
+		Message:            "all upstream resources are applied",
+		ObservedGeneration: managedKSQL.Generation,
+	})
+
+	if c.publishGraph {
+		if err := c.syncGraphConfigMap(managedKSQL, stmts); err != nil {
+			utilruntime.HandleError(fmt.Errorf("error publishing graph: %v", err))
+		}
+	}
+
+	if managedKSQL.DryRun {
+		plan, err := c.plan(managedKSQL, stmts, props)
+		if err != nil {
+			return err
+		}
+		managedKSQL.Status.Plan = plan
+		managedKSQL.Status.Applied = ksqloperatorv1alpha1.StatusPending
+		return c.updateManagedKSQLStatus(managedKSQL)
+	}
+	managedKSQL.Status.Plan = nil
+
+	if len(managedKSQL.RequireApprovalFor) > 0 {
+		awaiting, err := c.awaitingApproval(managedKSQL, stmts, props)
+		if err != nil {
+			return err
+		}
+		if awaiting {
+			// we'll be queued again when the approval annotation is set
+			managedKSQL.Status.Applied = ksqloperatorv1alpha1.StatusPending
+			return c.updateManagedKSQLStatus(managedKSQL)
+		}
+	} else {
+		meta.RemoveStatusCondition(&managedKSQL.Status.Conditions, ksqloperatorv1alpha1.ConditionAwaitingApproval)
+	}
 
 	defer c.updateManagedKSQLStatus(managedKSQL)
 
 	managedKSQL.Status.Applied = ksqloperatorv1alpha1.StatusPending
 
+	if err := c.apply(managedKSQL, stmts, props); err != nil {
+		if err == errMigrationStarted {
+			// we'll be synced again when the migration is seen in the status
+			return nil
+		}
+		return err
+	}
+	managedKSQL.Status.Applied = ksqloperatorv1alpha1.StatusApplied
+
+	if requeue := migrationRequeue(managedKSQL); requeue > 0 {
+		c.workqueue.AddAfter(key, requeue)
+	}
+
+	return nil
+}
+
+// apply runs stmts in order, recording the status of each in managedKSQL, and then drops or terminates what it
+// tracks which is no longer part of a statement
+func (c *Controller) apply(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, stmts []ksqlparser.Stmt, props map[string]map[string]string) error {
+	recorder, planning := c.ksqlClient.(*dryRunClient)
+
 	//check status in stmt order by name
 	stmtNames := map[string]ksqlparser.Stmt{}
 	for _, stmt := range stmts {
 		name := stmt.GetName()
 		stmtNames[name] = stmt
 		commandStatus := managedKSQL.Status.ItemStatus[name]
+		if planning {
+			recorder.name = name
+		}
+		if startMigration(managedKSQL, stmt, &commandStatus) {
+			managedKSQL.Status.ItemStatus[name] = commandStatus
+			return errMigrationStarted
+		}
 
-		err := c.processStmt(stmt, &commandStatus)
+		ctx := ksqlclient.WithStreamsProperties(context.Background(), props[name])
+		err := c.processStmt(ctx, stmt, &commandStatus)
 		managedKSQL.Status.ItemStatus[name] = commandStatus
 		if err != nil {
 			return err
 		}
 	}
 
+	// old versions are kept until their new versions have caught up and the statements reading from them have moved
+	// over, which is only once they're synced again
+	retired := retiring(managedKSQL)
+	// as are those still used by the queries of other resources, dropping them would terminate those queries
+	readers, err := c.foreignReaders(managedKSQL)
+	if err != nil {
+		return err
+	}
+	for previous, queries := range readers {
+		retired[previous] = true
+		if !planning {
+			c.recorder.Event(managedKSQL, corev1.EventTypeWarning, ksqloperatorv1alpha1.ReasonMigrationBlocked,
+				fmt.Sprintf("%s is kept until queries %s stop using it", previous, strings.Join(queries, ", ")))
+		}
+	}
+	if err := c.advanceMigrations(managedKSQL); err != nil {
+		return err
+	}
+
 	// find any which we are tracking which no longer exist in the status and drop/terminate them
 	var dropped []string
+	for k := range managedKSQL.Status.ItemStatus {
+		if _, ok := stmtNames[k]; !ok && !retired[k] {
+			dropped = append(dropped, k)
+		}
+	}
+	// in a repeatable order so that a plan doesn't change between syncs
+	sort.Strings(dropped)
 
-	for k, v := range managedKSQL.Status.ItemStatus {
-		if _, ok := stmtNames[k]; ok {
+	for _, k := range dropped {
+		v := managedKSQL.Status.ItemStatus[k]
+		if planning {
+			recorder.name = k
+		}
+		if v.Connector != nil {
+			// a connector is tracked by its name
+			if err := c.DropConnector(k); err != nil {
+				return err
+			}
 			continue
 		}
-		dropped = append(dropped, k)
 		if v.CommandID == "" || v.QueryID == "" {
 			klog.Warning(fmt.Sprintf("ignoring [%s] which does not form part of a current stmt and is missing a command/query identifier", k))
 			// we have no reference to manage this
@@ -431,8 +853,7 @@ func (c *Controller) syncHandler(key string) error {
 	for _, d := range dropped {
 		delete(managedKSQL.Status.ItemStatus, d)
 	}
-
-	managedKSQL.Status.Applied = ksqloperatorv1alpha1.StatusApplied
+	completeMigrations(managedKSQL)
 
 	return nil
 }
@@ -445,7 +866,7 @@ func (c *Controller) parseTypeAndNameFromCommand(v ksqloperatorv1alpha1.CommandS
 	}
 	t := commandParts[0]
 	if len(commandParts[1]) < 2 {
-		return "", "", fmt.Errorf("command id name part '%s' was not in the expected format", v.CommandID[1])
+		return "", "", fmt.Errorf("command id name part '%s' was not in the expected format", commandParts[1])
 	}
 	// the name is quoted so trim the quotes
 	n := commandParts[1][1 : len(commandParts[1])-2]
@@ -463,6 +884,63 @@ func (c *Controller) enqueueManagedKSQL(obj interface{}) {
 	c.workqueue.Add(key)
 }
 
+// seedIndex adds every ManagedKSQL which can be parsed to the source index
+func (c *Controller) seedIndex() error {
+	managedKSQLs, err := c.managedKSQLLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing resources: %v", err)
+	}
+	for _, managedKSQL := range managedKSQLs {
+		key, err := c.getKeyForResource(managedKSQL)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		stmts, _, _, err := c.parseStatement(managedKSQL)
+		if err != nil {
+			// it'll be reported when it's synced
+			continue
+		}
+		c.index.Set(key, managedKSQL, stmts)
+	}
+	return nil
+}
+
+// enqueueDependants puts the ManagedKSQLs reading sources declared by obj onto the work queue
+func (c *Controller) enqueueDependants(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, dependant := range c.index.Dependants(key) {
+		klog.V(4).Infof("queueing %s which depends on %s", dependant, key)
+		c.workqueue.Add(dependant)
+	}
+}
+
+// waitingOn describes the upstream ManagedKSQLs, and the sources read from them, which are not yet applied
+func (c *Controller) waitingOn(key string) []string {
+	var result []string
+	for owner, sources := range c.index.Upstream(key) {
+		namespace, name, err := c.splitMetaNamespaceKey(owner)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		upstream, err := c.managedKSQLLister.ManagedKSQLs(namespace).Get(name)
+		if err != nil {
+			// it's gone so there is nothing to wait on
+			continue
+		}
+		if upstream.Status.Applied != ksqloperatorv1alpha1.StatusApplied {
+			result = append(result, fmt.Sprintf("%s for %s", owner, strings.Join(sources, ", ")))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 func (c *Controller) setManagedResourceStatus(ManagedKSQL *ksqloperatorv1alpha1.ManagedKSQL, status ksqloperatorv1alpha1.ResourceStatus) {
 	cp := ManagedKSQL.DeepCopy()
 	cp.Status.Applied = status
@@ -472,7 +950,32 @@ func (c *Controller) setManagedResourceStatus(ManagedKSQL *ksqloperatorv1alpha1.
 	}
 }
 
+// setConditionError records err against the condition in the status and as an event
+func (c *Controller) setConditionError(ManagedKSQL *ksqloperatorv1alpha1.ManagedKSQL, conditionType string, reason string, conditionErr error) {
+	cp := ManagedKSQL.DeepCopy()
+	cp.Status.Applied = ksqloperatorv1alpha1.StatusFailed
+	meta.SetStatusCondition(&cp.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            conditionErr.Error(),
+		ObservedGeneration: cp.Generation,
+	})
+	c.recorder.Event(ManagedKSQL, corev1.EventTypeWarning, reason, conditionErr.Error())
+	err := c.updateManagedKSQLStatus(cp)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("error updating status: %v", err))
+	}
+}
+
 func (c *Controller) updateManagedKSQLStatus(ManagedKSQL *ksqloperatorv1alpha1.ManagedKSQL) error {
+	ManagedKSQL.Status.ReadyQueries = 0
+	for _, commandStatus := range ManagedKSQL.Status.ItemStatus {
+		if commandStatus.QueryID != "" && commandStatus.Status != ksqloperatorv1alpha1.StatusError &&
+			commandStatus.Status != ksqloperatorv1alpha1.StatusTerminated {
+			ManagedKSQL.Status.ReadyQueries++
+		}
+	}
 	// If the CustomResourceSubResources feature gate is not enabled,
 	// we must use Update instead of UpdateStatus to update the Status block of the KSQLDefinition resource.
 	// UpdateStatus will not allow changes to the Spec of the resource,
@@ -482,12 +985,22 @@ func (c *Controller) updateManagedKSQLStatus(ManagedKSQL *ksqloperatorv1alpha1.M
 	return err
 }
 
-func (c *Controller) processStmt(stmt ksqlparser.Stmt, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+func (c *Controller) processStmt(ctx context.Context, stmt ksqlparser.Stmt, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
 	klog.V(5).Infof("processing stmt '%s'", stmt.GetName())
 	ksql := stmt.String()
-	hash := DefaultHasher(ksql)
+	hash := StmtHasher(ksql)
+	upgradeLegacySha(&commandStatus.QuerySha, ksql)
+	if createStmt, ok := stmt.(ksqlparser.CreateStmt); ok {
+		switch createStmt.GetObjectType() {
+		case ksqlparser.CreateObjectTypeType:
+			return c.processCreateType(ctx, stmt.GetName(), ksql, hash, commandStatus)
+		case ksqlparser.CreateObjectTypeConnector:
+			return c.processConnector(stmt, ksql, hash, commandStatus)
+		}
+	}
 	switch stmt.GetActionType() {
 	case ksqlparser.StmtTypeCreate:
+		exists := commandStatus.CommandID != ""
 		if commandStatus.CommandID == "" {
 			// lets describe it to see if it already exists
 			resp, err := c.ksqlClient.Describe(context.Background(), stmt.GetName())
@@ -510,14 +1023,21 @@ func (c *Controller) processStmt(stmt ksqlparser.Stmt, commandStatus *ksqloperat
 					utilruntime.HandleError(err)
 					return nil
 				}
-				stmtHash := DefaultHasher((*modelResult)[0].SourceDescription.Statement)
+				stmtHash := StmtHasher((*modelResult)[0].SourceDescription.Statement)
 				commandStatus.StatusSha = stmtHash
 
-				// set the stage so that the below drop code executes
+				// set the stage so that the below upgrade code executes
 				commandStatus.QuerySha = ""
+				exists = true
 			}
 		}
 		if commandStatus.QuerySha != hash {
+			if exists {
+				replaced, err := c.upgrade(ctx, stmt, hash, commandStatus)
+				if err != nil || replaced {
+					return err
+				}
+			}
 			klog.V(5).Info("querySha differs issuing drop")
 			commandStatus.CommandID = ""
 			t := stmt.(ksqlparser.CreateStmt).GetObjectType()
@@ -525,6 +1045,14 @@ func (c *Controller) processStmt(stmt ksqlparser.Stmt, commandStatus *ksqloperat
 			if err != nil && err != ErrNotFound {
 				return err
 			}
+			if exists {
+				upgrade := &ksqloperatorv1alpha1.UpgradeStatus{Outcome: ksqloperatorv1alpha1.UpgradeRecreated, QuerySha: hash}
+				if commandStatus.Upgrade != nil && commandStatus.Upgrade.QuerySha == hash {
+					// keep why it couldn't be replaced
+					upgrade.Message = commandStatus.Upgrade.Message
+				}
+				commandStatus.Upgrade = upgrade
+			}
 		}
 		fallthrough
 	case ksqlparser.StmtTypeCreateOrReplace:
@@ -532,7 +1060,7 @@ func (c *Controller) processStmt(stmt ksqlparser.Stmt, commandStatus *ksqloperat
 		if commandStatus.CommandID == "" || commandStatus.QuerySha != hash {
 			klog.V(5).Info("commandId is not set or querySha differs issuing create")
 
-			if err := c.processCreateOrReplaceStmt(ksql, hash, commandStatus); err != nil {
+			if err := c.processCreateOrReplaceStmt(ctx, ksql, hash, commandStatus); err != nil {
 				return err
 			}
 			return nil
@@ -579,7 +1107,8 @@ func (c *Controller) processStmt(stmt ksqlparser.Stmt, commandStatus *ksqloperat
 				utilruntime.HandleError(err)
 				return nil
 			}
-			stmtHash := DefaultHasher((*modelResult)[0].SourceDescription.Statement)
+			upgradeLegacySha(&commandStatus.StatusSha, (*modelResult)[0].SourceDescription.Statement)
+			stmtHash := StmtHasher((*modelResult)[0].SourceDescription.Statement)
 
 			if stmtHash != commandStatus.StatusSha {
 				klog.V(4).Info("query stmt differs from what was last seen")
@@ -589,9 +1118,15 @@ func (c *Controller) processStmt(stmt ksqlparser.Stmt, commandStatus *ksqloperat
 
 		}
 	case ksqlparser.StmtTypeInsert:
-		if err := c.processInsert(ksql, hash, commandStatus); err != nil {
+		if err := c.processInsert(ctx, ksql, hash, commandStatus); err != nil {
 			return err
 		}
+	case ksqlparser.StmtTypeInsertValues:
+		return c.processInsertValues(ctx, ksql, hash, commandStatus)
+	case ksqlparser.StmtTypeDrop:
+		return c.processDropConnector(ksql, hash, commandStatus)
+	case ksqlparser.StmtTypeDescribe:
+		// there's nothing to apply
 	default:
 		// TODO error unsupported stmt type
 		return fmt.Errorf("unsupported stmt type %s", stmt.GetActionType())
@@ -600,8 +1135,8 @@ func (c *Controller) processStmt(stmt ksqlparser.Stmt, commandStatus *ksqloperat
 	return nil
 }
 
-func (c *Controller) processCreateOrReplaceStmt(ksql string, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
-	result, err := c.ksqlClient.CreateDropTerminate(context.Background(), ksql)
+func (c *Controller) processCreateOrReplaceStmt(ctx context.Context, ksql string, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+	result, err := c.ksqlClient.CreateDropTerminate(ctx, ksql)
 	if err != nil {
 		// this could be a transient issue so queue for retry
 		return err
@@ -623,25 +1158,69 @@ func (c *Controller) processCreateOrReplaceStmt(ksql string, queryHash string, c
 		}
 
 		// record the outcome
-		response := (*modelResult)[0]
-		commandStatus.Status, err = ksqloperatorv1alpha1.ParseCommandStatus(response.CommandStatus.Status)
-		if err != nil {
-			return err
-		}
-		commandStatus.CommandID = response.CommandId
-		commandStatus.QueryID = response.CommandStatus.QueryId
-		commandStatus.StatusSha = DefaultHasher(response.StatementText)
-		commandStatus.QuerySha = queryHash
-
-		return nil
+		return recordCreated((*modelResult)[0], queryHash, commandStatus)
 	default:
 		return fmt.Errorf("unexpected result type %t", result)
 	}
 }
 
-func (c *Controller) ExecuteInsert(ksql, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+// recordCreated records the response to a CREATE or CREATE OR REPLACE in commandStatus
+func recordCreated(response swagger.CreateDropTerminateResponseItem, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+	status, err := ksqloperatorv1alpha1.ParseCommandStatus(response.CommandStatus.Status)
+	if err != nil {
+		return err
+	}
+	commandStatus.Status = status
+	commandStatus.CommandID = response.CommandId
+	commandStatus.QueryID = response.CommandStatus.QueryId
+	commandStatus.StatusSha = StmtHasher(response.StatementText)
+	commandStatus.QuerySha = queryHash
+	return nil
+}
+
+// upgrade changes the existing stream or table of stmt in place with CREATE OR REPLACE and reports whether it was
+// replaced. When ksqlDB rejects the change that's recorded and an error returned, nothing is dropped until it's synced
+// again so the stream or table being dropped and created again is planned and can be approved first.
+func (c *Controller) upgrade(ctx context.Context, stmt ksqlparser.Stmt, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) (bool, error) {
+	if commandStatus.Upgrade != nil && commandStatus.Upgrade.Outcome == ksqloperatorv1alpha1.UpgradeRejected &&
+		commandStatus.Upgrade.QuerySha == queryHash {
+		return false, nil
+	}
+	replace, ok := ksqlparser.OrReplace(stmt)
+	if !ok {
+		return false, nil
+	}
+	klog.V(5).Info("querySha differs issuing create or replace")
+	result, err := c.ksqlClient.CreateDropTerminate(ctx, replace.String())
+	if err != nil {
+		// this could be a transient issue so queue for retry
+		return false, err
+	}
+	switch result := result.(type) {
+	case *swagger.ModelError:
+		commandStatus.Upgrade = &ksqloperatorv1alpha1.UpgradeStatus{
+			Outcome:  ksqloperatorv1alpha1.UpgradeRejected,
+			QuerySha: queryHash,
+			Message:  result.Message,
+		}
+		return false, fmt.Errorf("%s can't be replaced, it'll be dropped and created again: %s", stmt.GetName(), result.Message)
+	case *[]swagger.CreateDropTerminateResponseItem:
+		if len(*result) != 1 {
+			return false, fmt.Errorf("expected only one response from ksql but got %d, \n %v", len(*result), result)
+		}
+		if err := recordCreated((*result)[0], queryHash, commandStatus); err != nil {
+			return false, err
+		}
+		commandStatus.Upgrade = &ksqloperatorv1alpha1.UpgradeStatus{Outcome: ksqloperatorv1alpha1.UpgradeReplaced, QuerySha: queryHash}
+		return true, nil
+	default:
+		return false, fmt.Errorf("unexpected result type %t", result)
+	}
+}
+
+func (c *Controller) ExecuteInsert(ctx context.Context, ksql, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
 	// execute this
-	result, err := c.ksqlClient.CreateDropTerminate(context.Background(), ksql)
+	result, err := c.ksqlClient.CreateDropTerminate(ctx, ksql)
 	if err != nil {
 		// this could be a transient issue so queue for retry
 		return err
@@ -670,7 +1249,7 @@ func (c *Controller) ExecuteInsert(ksql, queryHash string, commandStatus *ksqlop
 		}
 		commandStatus.CommandID = response.CommandId
 		commandStatus.QueryID = response.CommandStatus.QueryId
-		commandStatus.StatusSha = DefaultHasher(response.StatementText)
+		commandStatus.StatusSha = StmtHasher(response.StatementText)
 		commandStatus.QuerySha = queryHash
 
 		return nil
@@ -721,10 +1300,10 @@ func (c *Controller) WaitForSuccess(commandID string) error {
 	return fmt.Errorf("status was not resolved in %d retries", i)
 }
 
-func (c *Controller) processInsert(ksql string, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+func (c *Controller) processInsert(ctx context.Context, ksql string, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
 	klog.V(5).Info("processing insert stmt")
 	if commandStatus.QueryID == "" {
-		if err := c.ExecuteInsert(ksql, queryHash, commandStatus); err != nil {
+		if err := c.ExecuteInsert(ctx, ksql, queryHash, commandStatus); err != nil {
 			return err
 		}
 	}
@@ -756,7 +1335,8 @@ func (c *Controller) processInsert(ksql string, queryHash string, commandStatus
 			utilruntime.HandleError(err)
 			return nil
 		}
-		newStatusSha := DefaultHasher((*result)[0].QueryDescription.StatementText)
+		upgradeLegacySha(&commandStatus.StatusSha, (*result)[0].QueryDescription.StatementText)
+		newStatusSha := StmtHasher((*result)[0].QueryDescription.StatementText)
 		commandStatus.Status, err = ksqloperatorv1alpha1.ParseCommandStatus((*result)[0].QueryDescription.State)
 		if err != nil {
 			utilruntime.HandleError(err)
@@ -787,7 +1367,7 @@ func (c *Controller) processInsert(ksql string, queryHash string, commandStatus
 			}
 			// issue create
 			klog.V(5).Info("issuing insert")
-			if err := c.ExecuteInsert(ksql, queryHash, commandStatus); err != nil {
+			if err := c.ExecuteInsert(ctx, ksql, queryHash, commandStatus); err != nil {
 				return err
 			}
 		}
@@ -795,6 +1375,34 @@ func (c *Controller) processInsert(ksql string, queryHash string, commandStatus
 	return nil
 }
 
+// processInsertValues inserts the row of an INSERT INTO ... VALUES, it's only inserted again when the statement changes
+func (c *Controller) processInsertValues(ctx context.Context, ksql string, queryHash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+	if commandStatus.QuerySha == queryHash {
+		klog.V(5).Info("values have already been inserted")
+		return nil
+	}
+	klog.V(5).Info("inserting values")
+	result, err := c.ksqlClient.CreateDropTerminate(ctx, ksql)
+	if err != nil {
+		// this could be a transient issue so queue for retry
+		return err
+	}
+	switch result.(type) {
+	case *swagger.ModelError:
+		commandStatus.Status = ksqloperatorv1alpha1.StatusError
+		modelErr := result.(*swagger.ModelError)
+		return fmt.Errorf("error response from ksql: (%f0) %s\n%s",
+			modelErr.ErrorCode, modelErr.Message, strings.Join(modelErr.StackTrace, "\n"))
+	case *[]swagger.CreateDropTerminateResponseItem:
+		// ksqlDB doesn't return anything for an insert of values
+		commandStatus.Status = ksqloperatorv1alpha1.StatusSuccess
+		commandStatus.QuerySha = queryHash
+		return nil
+	default:
+		return fmt.Errorf("unexpected result type %t", result)
+	}
+}
+
 const (
 	ErrNotFound = Error("not found")
 )
@@ -832,6 +1440,63 @@ func (c *Controller) Terminate(queryID string) error {
 	return nil
 }
 
+// syncGraphConfigMap writes the graph of stmts to a ConfigMap owned by the ManagedKSQL
+func (c *Controller) syncGraphConfigMap(ManagedKSQL *ksqloperatorv1alpha1.ManagedKSQL, stmts []ksqlparser.Stmt) error {
+	g := ksqlparser.NewGraph(stmts)
+	graphJSON, err := g.JSON()
+	if err != nil {
+		return err
+	}
+	data := map[string]string{
+		"graph.dot":  g.DOT(),
+		"graph.mmd":  g.Mermaid(),
+		"graph.json": string(graphJSON),
+	}
+
+	configMaps := c.kubeclientset.CoreV1().ConfigMaps(ManagedKSQL.Namespace)
+	name := ManagedKSQL.Name + graphConfigMapSuffix
+	existing, err := configMaps.Get(context.Background(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configMaps.Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ManagedKSQL.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(ManagedKSQL, ksqloperatorv1alpha1.SchemeGroupVersion.WithKind("ManagedKSQL")),
+				},
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if diff := deep.Equal(existing.Data, data); diff == nil {
+		return nil
+	}
+	cp := existing.DeepCopy()
+	cp.Data = data
+	_, err = configMaps.Update(context.Background(), cp, metav1.UpdateOptions{})
+	return err
+}
+
+// sourceExists reports whether a stream, table or custom type exists on the ksql server
+func (c *Controller) sourceExists(name string) (bool, error) {
+	resp, err := c.ksqlClient.Describe(context.Background(), name)
+	if err != nil {
+		return false, err
+	}
+	if modelErr, ok := resp.(*swagger.ModelError); ok {
+		if modelErr.ErrorCode == ksqlclient.ErrCodeNotFound {
+			return c.typeExists(name)
+		}
+		return false, fmt.Errorf("error response from ksql: (%0f) %s\n%s",
+			modelErr.ErrorCode, modelErr.Message, strings.Join(modelErr.StackTrace, "\n"))
+	}
+	return true, nil
+}
+
 func (c *Controller) DropTableStreamChain(t string, n string) error {
 	resp, err := c.ksqlClient.Describe(context.Background(), n)
 	if err != nil {
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ksql_operator/ksqlclient/swagger"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// typeExists reports whether a custom type exists on the ksql server
+func (c *Controller) typeExists(name string) (bool, error) {
+	resp, err := c.ksqlClient.Execute(context.Background(), "SHOW TYPES;", &[]swagger.ShowListResponse{})
+	if err != nil {
+		return false, err
+	}
+	switch resp := resp.(type) {
+	case *swagger.ModelError:
+		return false, fmt.Errorf("error response from ksql: (%0f) %s\n%s",
+			resp.ErrorCode, resp.Message, strings.Join(resp.StackTrace, "\n"))
+	case *[]swagger.ShowListResponse:
+		for _, item := range *resp {
+			for t := range item.Types {
+				if strings.EqualFold(t, name) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// processCreateType creates the custom type declared by ksql. A type can't be altered so one which has changed, or
+// which was created outside of this resource, is dropped and created again.
+func (c *Controller) processCreateType(ctx context.Context, name, ksql, hash string, commandStatus *ksqloperatorv1alpha1.CommandStatus) error {
+	exists, err := c.typeExists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if commandStatus.QuerySha == hash {
+			return nil
+		}
+		if err := c.DropType(name); err != nil {
+			return err
+		}
+	}
+	return c.processCreateOrReplaceStmt(ctx, ksql, hash, commandStatus)
+}
+
+// DropType drops the custom type n if it exists
+func (c *Controller) DropType(n string) error {
+	result, err := c.ksqlClient.CreateDropTerminate(context.Background(), fmt.Sprintf("DROP TYPE IF EXISTS %s;", n))
+	if err != nil {
+		return fmt.Errorf("error dropping type %s: %v", n, err)
+	}
+	switch result := result.(type) {
+	case *swagger.ModelError:
+		return fmt.Errorf("error response from ksql: (%f0) %s\n%s",
+			result.ErrorCode, result.Message, strings.Join(result.StackTrace, "\n"))
+	case *[]swagger.CreateDropTerminateResponseItem:
+		if len(*result) != 1 {
+			// this is likely to be unrecoverable
+			return fmt.Errorf("expected only one response from ksql but got %d, \n %v", len(*result), result)
+		}
+		stat, err := ksqloperatorv1alpha1.ParseCommandStatus((*result)[0].CommandStatus.Status)
+		if err != nil {
+			return err
+		}
+		if stat == ksqloperatorv1alpha1.StatusError {
+			return fmt.Errorf("command '%s' errored", (*result)[0].CommandId)
+		}
+		if stat != ksqloperatorv1alpha1.StatusSuccess {
+			return c.WaitForSuccess((*result)[0].CommandId)
+		}
+	}
+	return nil
+}
@@ -31,5 +31,12 @@ bash "${CODEGEN_PKG}"/generate-groups.sh "all" \
   --output-base "$(dirname "${BASH_SOURCE[0]}")/../.." \
   --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
 
+# generate the CRDs from the kubebuilder markers of the types with controller-gen, install it with
+#   go install sigs.k8s.io/controller-tools/cmd/controller-gen@v0.4.1
+CONTROLLER_GEN=${CONTROLLER_GEN:-$(command -v controller-gen || echo "${GOPATH:-${HOME}/go}/bin/controller-gen")}
+"${CONTROLLER_GEN}" crd:crdVersions=v1 \
+  paths="${SCRIPT_ROOT}/pkg/apis/..." \
+  output:crd:dir="${SCRIPT_ROOT}/manifests/crds"
+
 # To use your own boilerplate text append:
 #   --go-header-file "${SCRIPT_ROOT}"/hack/custom-boilerplate.go.txt
\ No newline at end of file
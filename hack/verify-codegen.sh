@@ -22,6 +22,8 @@ SCRIPT_ROOT=$(dirname "${BASH_SOURCE[0]}")/..
 
 DIFFROOT="${SCRIPT_ROOT}/pkg"
 TMP_DIFFROOT="${SCRIPT_ROOT}/_tmp/pkg"
+CRDROOT="${SCRIPT_ROOT}/manifests/crds"
+TMP_CRDROOT="${SCRIPT_ROOT}/_tmp/crds"
 _tmp="${SCRIPT_ROOT}/_tmp"
 
 cleanup() {
@@ -31,18 +33,21 @@ trap "cleanup" EXIT SIGINT
 
 cleanup
 
-mkdir -p "${TMP_DIFFROOT}"
+mkdir -p "${TMP_DIFFROOT}" "${TMP_CRDROOT}"
 cp -a "${DIFFROOT}"/* "${TMP_DIFFROOT}"
+cp -a "${CRDROOT}"/* "${TMP_CRDROOT}"
 
 "${SCRIPT_ROOT}/hack/update-codegen.sh"
 echo "diffing ${DIFFROOT} against freshly generated codegen"
 ret=0
 diff -Naupr "${DIFFROOT}" "${TMP_DIFFROOT}" || ret=$?
+diff -Naupr "${CRDROOT}" "${TMP_CRDROOT}" || ret=$?
 cp -a "${TMP_DIFFROOT}"/* "${DIFFROOT}"
+cp -a "${TMP_CRDROOT}"/* "${CRDROOT}"
 if [[ $ret -eq 0 ]]
 then
-  echo "${DIFFROOT} up to date."
+  echo "${DIFFROOT} and ${CRDROOT} up to date."
 else
-  echo "${DIFFROOT} is out of date. Please run hack/update-codegen.sh"
+  echo "${DIFFROOT} or ${CRDROOT} is out of date. Please run hack/update-codegen.sh"
   exit 1
 fi
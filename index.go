@@ -0,0 +1,207 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// claim is a ManagedKSQL declaring a source
+type claim struct {
+	key string
+	// held is true when the ManagedKSQL has recorded that it owns the source in its status
+	held    bool
+	created time.Time
+}
+
+// before reports whether c takes precedence over o
+func (c claim) before(o claim) bool {
+	if c.held != o.held {
+		return c.held
+	}
+	if !c.created.Equal(o.created) {
+		return c.created.Before(o.created)
+	}
+	return c.key < o.key
+}
+
+// sourceIndex is a thread safe index of the streams and tables declared by each ManagedKSQL across the cluster
+// and of the sources each reads which it doesn't declare itself.
+// ksqlDB has nowhere to tag a stream or table with its owner so ownership is decided here, a source is owned by the
+// ManagedKSQL which holds it in its status or failing that the oldest to declare it.
+type sourceIndex struct {
+	lock sync.RWMutex
+	// claims maps the name of a source to the ManagedKSQLs which declare it, the owner first
+	claims map[string][]claim
+	// declares maps the key of a ManagedKSQL to the names of the sources it declares
+	declares map[string][]string
+	// reads maps the key of a ManagedKSQL to the names of the sources it reads which it doesn't declare
+	reads map[string][]string
+}
+
+func newSourceIndex() *sourceIndex {
+	return &sourceIndex{
+		claims:   map[string][]claim{},
+		declares: map[string][]string{},
+		reads:    map[string][]string{},
+	}
+}
+
+// Set records the sources declared and read by stmts for the ManagedKSQL with key
+func (i *sourceIndex) Set(key string, managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, stmts []ksqlparser.Stmt) {
+	declared := map[string]bool{}
+	for _, stmt := range stmts {
+		if _, ok := stmt.(ksqlparser.CreateStmt); ok {
+			declared[strings.ToUpper(stmt.GetName())] = true
+		}
+	}
+	read := map[string]bool{}
+	for _, stmt := range stmts {
+		for _, source := range ksqlparser.Dependencies(stmt) {
+			if source = strings.ToUpper(source); !declared[source] {
+				read[source] = true
+			}
+		}
+	}
+	held := map[string]bool{}
+	for _, source := range managedKSQL.Status.Owned {
+		held[strings.ToUpper(source)] = true
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.delete(key)
+	i.declares[key] = sortedKeys(declared)
+	i.reads[key] = sortedKeys(read)
+	for _, source := range i.declares[key] {
+		claims := append(i.claims[source], claim{
+			key:     key,
+			held:    held[source],
+			created: managedKSQL.CreationTimestamp.Time,
+		})
+		sort.Slice(claims, func(a, b int) bool { return claims[a].before(claims[b]) })
+		i.claims[source] = claims
+	}
+}
+
+// Delete removes the ManagedKSQL with key from the index
+func (i *sourceIndex) Delete(key string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.delete(key)
+}
+
+func (i *sourceIndex) delete(key string) {
+	for _, source := range i.declares[key] {
+		var claims []claim
+		for _, c := range i.claims[source] {
+			if c.key != key {
+				claims = append(claims, c)
+			}
+		}
+		if len(claims) == 0 {
+			delete(i.claims, source)
+			continue
+		}
+		i.claims[source] = claims
+	}
+	delete(i.declares, key)
+	delete(i.reads, key)
+}
+
+// Owner returns the key of the ManagedKSQL which owns source
+func (i *sourceIndex) Owner(source string) (string, bool) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	claims := i.claims[strings.ToUpper(source)]
+	if len(claims) == 0 {
+		return "", false
+	}
+	return claims[0].key, true
+}
+
+// Owned returns the sources declared and owned by the ManagedKSQL with key
+func (i *sourceIndex) Owned(key string) []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	var result []string
+	for _, source := range i.declares[key] {
+		if i.claims[source][0].key == key {
+			result = append(result, source)
+		}
+	}
+	return result
+}
+
+// Conflicts returns the sources declared by the ManagedKSQL with key which are owned by another, keyed by source
+func (i *sourceIndex) Conflicts(key string) map[string]string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	result := map[string]string{}
+	for _, source := range i.declares[key] {
+		if owner := i.claims[source][0].key; owner != key {
+			result[source] = owner
+		}
+	}
+	return result
+}
+
+// Rivals returns the keys of the other ManagedKSQLs declaring a source declared by the ManagedKSQL with key
+func (i *sourceIndex) Rivals(key string) []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	rivals := map[string]bool{}
+	for _, source := range i.declares[key] {
+		for _, c := range i.claims[source] {
+			if c.key != key {
+				rivals[c.key] = true
+			}
+		}
+	}
+	return sortedKeys(rivals)
+}
+
+// Upstream returns the sources read by the ManagedKSQL with key grouped by the key of the ManagedKSQL owning them
+func (i *sourceIndex) Upstream(key string) map[string][]string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	result := map[string][]string{}
+	for _, source := range i.reads[key] {
+		if claims := i.claims[source]; len(claims) > 0 && claims[0].key != key {
+			result[claims[0].key] = append(result[claims[0].key], source)
+		}
+	}
+	return result
+}
+
+// Dependants returns the keys of the ManagedKSQLs which read a source declared by the ManagedKSQL with key
+func (i *sourceIndex) Dependants(key string) []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	declared := map[string]bool{}
+	for _, source := range i.declares[key] {
+		declared[source] = true
+	}
+	dependants := map[string]bool{}
+	for k, sources := range i.reads {
+		for _, source := range sources {
+			if k != key && declared[source] {
+				dependants[k] = true
+			}
+		}
+	}
+	return sortedKeys(dependants)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var result []string
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// indexed is a ManagedKSQL added to a sourceIndex in a test
+type indexed struct {
+	key     string
+	created time.Time
+	owned   []string
+	sql     string
+}
+
+func newTestIndex(t *testing.T, resources []indexed) *sourceIndex {
+	index := newSourceIndex()
+	for _, r := range resources {
+		stmts, err := ksqlparser.Parse(r.sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(r.created)},
+			Status:     ksqloperatorv1alpha1.ManagedKSQLStatus{Owned: r.owned},
+		}
+		index.Set(r.key, managedKSQL, stmts)
+	}
+	return index
+}
+
+func TestSourceIndexOwner(t *testing.T) {
+	older := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	sql := "CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON');"
+	tests := []struct {
+		name      string
+		resources []indexed
+		want      string
+	}{
+		{
+			name: "held",
+			resources: []indexed{
+				{key: "ns/older", created: older, sql: sql},
+				{key: "ns/newer", created: newer, owned: []string{"a"}, sql: sql},
+			},
+			want: "ns/newer",
+		},
+		{
+			name: "older",
+			resources: []indexed{
+				{key: "ns/newer", created: newer, sql: sql},
+				{key: "ns/older", created: older, sql: sql},
+			},
+			want: "ns/older",
+		},
+		{
+			name: "key",
+			resources: []indexed{
+				{key: "ns/b", created: older, sql: sql},
+				{key: "ns/a", created: older, sql: sql},
+			},
+			want: "ns/a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := newTestIndex(t, tt.resources)
+			if got, _ := index.Owner("A"); got != tt.want {
+				t.Errorf("Owner() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceIndex(t *testing.T) {
+	older := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	index := newTestIndex(t, []indexed{
+		{key: "ns/producer", created: older, sql: `
+CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON');
+CREATE STREAM b AS SELECT * FROM a EMIT CHANGES;`},
+		{key: "ns/rival", created: newer, sql: `
+CREATE STREAM b AS SELECT * FROM a EMIT CHANGES;
+CREATE STREAM c AS SELECT * FROM b EMIT CHANGES;`},
+		{key: "ns/consumer", created: newer, sql: `
+CREATE STREAM d AS SELECT * FROM b EMIT CHANGES;
+CREATE STREAM e AS SELECT * FROM c EMIT CHANGES;`},
+	})
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "owned", got: index.Owned("ns/rival"), want: []string{"C"}},
+		{name: "conflicts", got: index.Conflicts("ns/rival"), want: map[string]string{"B": "ns/producer"}},
+		{name: "no conflicts", got: index.Conflicts("ns/producer"), want: map[string]string{}},
+		{name: "rivals", got: index.Rivals("ns/producer"), want: []string{"ns/rival"}},
+		{
+			name: "upstream",
+			got:  index.Upstream("ns/consumer"),
+			want: map[string][]string{"ns/producer": {"B"}, "ns/rival": {"C"}},
+		},
+		{name: "upstream of a rival", got: index.Upstream("ns/rival"), want: map[string][]string{"ns/producer": {"A"}}},
+		{name: "dependants", got: index.Dependants("ns/producer"), want: []string{"ns/consumer", "ns/rival"}},
+		{name: "no dependants", got: index.Dependants("ns/consumer"), want: []string(nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(tt.got, tt.want); diff != nil {
+				t.Errorf("got = %v, want %v: %v", tt.got, tt.want, diff)
+			}
+		})
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		index.Delete("ns/producer")
+		if got, _ := index.Owner("B"); got != "ns/rival" {
+			t.Errorf("Owner() got = %s, want ns/rival", got)
+		}
+		if _, ok := index.Owner("A"); ok {
+			t.Errorf("Owner() A is still owned")
+		}
+	})
+}
+
+func TestConflicts(t *testing.T) {
+	older := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	connector := "CREATE SOURCE CONNECTOR orders WITH ('connector.class'='JdbcSourceConnector');"
+	c := &Controller{index: newTestIndex(t, []indexed{
+		{key: "ns/owner", created: older, sql: connector},
+		{key: "ns/rival", created: older.Add(time.Hour), sql: "CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON');"},
+	})}
+	tests := []struct {
+		name string
+		key  string
+		sql  string
+		want map[string]string
+	}{
+		{
+			name: "drop owned by another",
+			key:  "ns/rival",
+			sql:  "DROP CONNECTOR orders;",
+			want: map[string]string{"ORDERS": "ns/owner"},
+		},
+		{
+			name: "drop own",
+			key:  "ns/owner",
+			sql:  "DROP CONNECTOR orders;",
+			want: map[string]string{},
+		},
+		{
+			name: "drop unowned",
+			key:  "ns/rival",
+			sql:  "DROP CONNECTOR IF EXISTS legacy;",
+			want: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := ksqlparser.Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(c.conflicts(tt.key, stmts), tt.want); diff != nil {
+				t.Errorf("conflicts() %v", diff)
+			}
+		})
+	}
+}
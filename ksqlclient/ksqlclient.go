@@ -32,6 +32,20 @@ type client struct {
 	password string
 }
 
+// streamsPropertiesKey is the context key of the streams properties sent with each statement
+type streamsPropertiesKey struct{}
+
+// WithStreamsProperties returns a copy of ctx whose statements are executed with the streams properties props
+func WithStreamsProperties(ctx context.Context, props map[string]string) context.Context {
+	return context.WithValue(ctx, streamsPropertiesKey{}, props)
+}
+
+// StreamsProperties are the streams properties statements executed with ctx are sent with
+func StreamsProperties(ctx context.Context) map[string]string {
+	props, _ := ctx.Value(streamsPropertiesKey{}).(map[string]string)
+	return props
+}
+
 func New(baseUrl string, username string, password string) (*client, error) {
 	url, err := url.Parse(baseUrl)
 	return &client{baseURL: url, userName: username, password: password}, err
@@ -55,7 +69,7 @@ func (c client) CreateDropTerminate(ctx context.Context, sql string) (interface{
 	return c.Execute(ctx, sql, &[]swagger.CreateDropTerminateResponseItem{})
 }
 
-// execute a kql Statement
+// execute a kql Statement with any streams properties of ctx
 // the result is either swagger.ModelError{} or @result
 func (c client) Execute(ctx context.Context, ksql string, result interface{}) (interface{}, error) {
 	u, err := c.baseURL.Parse("ksql")
@@ -64,7 +78,8 @@ func (c client) Execute(ctx context.Context, ksql string, result interface{}) (i
 	}
 
 	requestBody := swagger.Statement{
-		Ksql: ksql,
+		Ksql:              ksql,
+		StreamsProperties: StreamsProperties(ctx),
 	}
 	requestBodyJson, err := json.Marshal(requestBody)
 	if err != nil {
@@ -0,0 +1,18 @@
+/*
+ * KSQL
+ *
+ * This is a swagger spec for ksqldb
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package swagger
+
+type ConnectorDescriptionResponseItem struct {
+	Type_          string                                  `json:"@type,omitempty"`
+	StatementText  string                                  `json:"statementText,omitempty"`
+	ConnectorClass string                                  `json:"connectorClass,omitempty"`
+	Status         *ConnectorDescriptionResponseItemStatus `json:"status,omitempty"`
+	Topics         []string                                `json:"topics,omitempty"`
+	ErrorMessage   string                                  `json:"errorMessage,omitempty"`
+}
@@ -0,0 +1,16 @@
+/*
+ * KSQL
+ *
+ * This is a swagger spec for ksqldb
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package swagger
+
+type ConnectorDescriptionResponseItemStatus struct {
+	Name      string                                           `json:"name,omitempty"`
+	Connector *ConnectorDescriptionResponseItemStatusConnector `json:"connector,omitempty"`
+	Tasks     []ConnectorDescriptionResponseItemStatusTasks    `json:"tasks,omitempty"`
+	Type_     string                                           `json:"type,omitempty"`
+}
@@ -0,0 +1,15 @@
+/*
+ * KSQL
+ *
+ * This is a swagger spec for ksqldb
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package swagger
+
+type ConnectorDescriptionResponseItemStatusConnector struct {
+	State    string `json:"state,omitempty"`
+	WorkerId string `json:"worker_id,omitempty"`
+	Trace    string `json:"trace,omitempty"`
+}
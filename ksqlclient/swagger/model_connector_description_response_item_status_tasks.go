@@ -0,0 +1,16 @@
+/*
+ * KSQL
+ *
+ * This is a swagger spec for ksqldb
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package swagger
+
+type ConnectorDescriptionResponseItemStatusTasks struct {
+	Id       int32  `json:"id,omitempty"`
+	State    string `json:"state,omitempty"`
+	WorkerId string `json:"worker_id,omitempty"`
+	Trace    string `json:"trace,omitempty"`
+}
@@ -0,0 +1,16 @@
+/*
+ * KSQL
+ *
+ * This is a swagger spec for ksqldb
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package swagger
+
+type ConnectorResponseItem struct {
+	Type_         string `json:"@type,omitempty"`
+	StatementText string `json:"statementText,omitempty"`
+	ConnectorName string `json:"connectorName,omitempty"`
+	ErrorMessage  string `json:"errorMessage,omitempty"`
+}
@@ -13,4 +13,5 @@ type ShowListResponse struct {
 	Streams    []ShowListResponseStreams `json:"streams,omitempty"`
 	Queries    []ShowListResponseQueries `json:"queries,omitempty"`
 	Properties *interface{}              `json:"properties,omitempty"`
+	Types      map[string]interface{}    `json:"types,omitempty"`
 }
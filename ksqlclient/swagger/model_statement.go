@@ -9,6 +9,6 @@
 package swagger
 
 type Statement struct {
-	Ksql              string                      `json:"ksql,omitempty"`
-	StreamsProperties *StatementStreamsProperties `json:"streamsProperties,omitempty"`
+	Ksql              string            `json:"ksql,omitempty"`
+	StreamsProperties map[string]string `json:"streamsProperties,omitempty"`
 }
@@ -5,6 +5,7 @@ import "fmt"
 type aliasedExpression struct {
 	Expression Expression
 	Alias      string
+	Comments   Comments
 }
 
 func (e aliasedExpression) String() string {
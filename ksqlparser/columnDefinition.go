@@ -10,6 +10,7 @@ type columnDefinition struct {
 	DataType  dataTypeDefinition
 	IsPrimary bool
 	IsKey     bool
+	Comments  Comments
 }
 
 func (d *columnDefinition) String() string {
@@ -29,13 +30,19 @@ func (d *columnDefinition) String() string {
 func (p *parser) parseColumnDefs() (*[]columnDefinition, error) {
 	var result []columnDefinition
 	for {
+		if len(result) > 0 {
+			// a comment after the comma belongs to the previous column
+			prev := &result[len(result)-1]
+			prev.Comments.Trailing = append(prev.Comments.Trailing, p.trailingComments()...)
+		}
+		def := columnDefinition{
+			Comments: Comments{Leading: p.leadingComments()},
+		}
 		item := p.pop()
 		if !isIdentifier(item) {
 			return nil, p.Error("[field]")
 		}
-		def := columnDefinition{
-			Name: item,
-		}
+		def.Name = item
 		dt, err := p.parseDataType()
 		if err != nil {
 			return nil, err
@@ -52,6 +59,7 @@ func (p *parser) parseColumnDefs() (*[]columnDefinition, error) {
 			p.popLength(l)
 			def.IsKey = true
 		}
+		def.Comments.Trailing = p.trailingComments()
 
 		result = append(result, def)
 
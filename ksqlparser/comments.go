@@ -0,0 +1,40 @@
+package ksqlparser
+
+// Comments are the comments found around a node in the source
+type Comments struct {
+	// Leading are the comments before the node
+	Leading []string
+	// Trailing are the comments following the node on the same line
+	Trailing []string
+}
+
+// comment is a comment seen by the tokeniser
+type comment struct {
+	Text string
+	// OwnLine is true when the comment was preceded by a newline rather than a token
+	OwnLine bool
+}
+
+type commented interface {
+	comments() *Comments
+}
+
+// trailingComments takes the pending comments which followed the last token on the same line
+func (p *parser) trailingComments() []string {
+	var result []string
+	for len(p.comments) > 0 && !p.comments[0].OwnLine {
+		result = append(result, p.comments[0].Text)
+		p.comments = p.comments[1:]
+	}
+	return result
+}
+
+// leadingComments takes all of the pending comments
+func (p *parser) leadingComments() []string {
+	var result []string
+	for _, c := range p.comments {
+		result = append(result, c.Text)
+	}
+	p.comments = nil
+	return result
+}
@@ -0,0 +1,139 @@
+package ksqlparser
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestParseComments(t *testing.T) {
+	sql := `-- the foo stream
+CREATE STREAM foo (
+  -- the key
+  a STRING, -- a
+  b STRING /* b */
+) WITH (KAFKA_TOPIC='foo'); -- after foo
+
+/* copy foo */
+INSERT INTO bar SELECT
+  a, -- first
+  b
+FROM foo
+WHERE a = 'x' -- only x
+  AND b = 'y';
+-- the end`
+
+	stmts, err := Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("Parse() got %d stmts, want 2", len(stmts))
+	}
+
+	foo := stmts[0].(*createStreamStmt)
+	if diff := deep.Equal(foo.Comments, Comments{
+		Leading:  []string{"-- the foo stream"},
+		Trailing: []string{"-- after foo"},
+	}); diff != nil {
+		t.Errorf("stmt comments %v", diff)
+	}
+	cols := *foo.Columns
+	if diff := deep.Equal(cols[0].Comments, Comments{
+		Leading:  []string{"-- the key"},
+		Trailing: []string{"-- a"},
+	}); diff != nil {
+		t.Errorf("column a comments %v", diff)
+	}
+	if diff := deep.Equal(cols[1].Comments, Comments{
+		Trailing: []string{"/* b */"},
+	}); diff != nil {
+		t.Errorf("column b comments %v", diff)
+	}
+
+	bar := stmts[1].(*insertIntoStmt)
+	if diff := deep.Equal(bar.Comments, Comments{
+		Leading:  []string{"/* copy foo */"},
+		Trailing: []string{"-- the end"},
+	}); diff != nil {
+		t.Errorf("stmt comments %v", diff)
+	}
+	if diff := deep.Equal(bar.Select.Expressions[0].Comments, Comments{
+		Trailing: []string{"-- first"},
+	}); diff != nil {
+		t.Errorf("select item comments %v", diff)
+	}
+	if diff := deep.Equal((*bar.Select.Where)[0].Comments, Comments{
+		Trailing: []string{"-- only x"},
+	}); diff != nil {
+		t.Errorf("condition comments %v", diff)
+	}
+
+	// comments must not change the statement or its name
+	plain, err := Parse("INSERT INTO bar SELECT a, b FROM foo WHERE a = 'x' AND b = 'y';")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bar.String() != plain[0].String() || bar.GetName() != plain[0].GetName() {
+		t.Errorf("String() got = %s, want %s", bar.String(), plain[0].String())
+	}
+}
+
+func TestFormatComments(t *testing.T) {
+	sql := `-- the foo stream
+CREATE STREAM foo (
+  -- the key
+  a STRING, -- a
+  b STRING /* b */
+) WITH (KAFKA_TOPIC='foo'); -- after foo
+
+INSERT INTO bar SELECT
+  a, -- first
+  b
+FROM foo
+WHERE a = 'x' -- only x
+  AND b = 'y' -- and y
+;`
+	stmts, err := Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultFormatOptions
+	opts.AlignWith = false
+	want := `-- the foo stream
+CREATE STREAM foo (
+  -- the key
+  a STRING, -- a
+  b STRING /* b */
+) WITH (KAFKA_TOPIC = 'foo'); -- after foo
+
+INSERT INTO bar
+SELECT
+  a, -- first
+  b
+FROM foo
+WHERE a = 'x' -- only x
+  AND b = 'y' /* and y */;
+`
+	if got := Format(stmts, opts); got != want {
+		t.Errorf("Format() got = \n%s\nwant\n%s", got, want)
+	}
+
+	opts.KeepComments = false
+	opts.OneSelectItemPerLine = false
+	want = `CREATE STREAM foo (
+  a STRING,
+  b STRING
+) WITH (KAFKA_TOPIC = 'foo');
+
+INSERT INTO bar
+SELECT a, b
+FROM foo
+WHERE a = 'x'
+  AND b = 'y';
+`
+	if got := Format(stmts, opts); got != want {
+		t.Errorf("Format() got = \n%s\nwant\n%s", got, want)
+	}
+}
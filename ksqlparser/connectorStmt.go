@@ -0,0 +1,212 @@
+package ksqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConnectorType is whether a connector reads into or writes out of Kafka
+type ConnectorType string
+
+const (
+	ConnectorTypeSource = ConnectorType("SOURCE")
+	ConnectorTypeSink   = ConnectorType("SINK")
+)
+
+// ConnectorPropertyClass is the connector property naming the class of the connector
+const ConnectorPropertyClass = "connector.class"
+
+// connectorProperty is a property of a connector as written, the key may be quoted
+type connectorProperty struct {
+	Key   string
+	Value string
+}
+
+type createConnectorStmt struct {
+	stmt
+	ConnectorType ConnectorType
+	IfNotExists   bool
+	Properties    []connectorProperty
+}
+
+func (s *createConnectorStmt) GetObjectType() CreateObjectType {
+	return CreateObjectTypeConnector
+}
+
+func (s *createConnectorStmt) GetActionType() StmtActionType {
+	return s.Type
+}
+
+func (s *createConnectorStmt) String() string {
+	sb := []string{string(s.stmt.Type), string(s.ConnectorType), ReservedConnector}
+	if s.IfNotExists {
+		sb = append(sb, ReservedIfNotExists)
+	}
+	var props []string
+	for _, prop := range s.Properties {
+		props = append(props, fmt.Sprintf("%s %s %s", prop.Key, ReservedEq, prop.Value))
+	}
+	sb = append(sb, s.Name, ReservedWith, ReservedOpenParens+strings.Join(props, ReservedComma+" ")+ReservedCloseParens,
+		ReservedEndOfStatement)
+	return strings.Join(sb, " ")
+}
+
+func (s *createConnectorStmt) GetName() string {
+	return s.Name
+}
+
+func (s *createConnectorStmt) GetDataSources() []string {
+	return nil
+}
+
+type dropConnectorStmt struct {
+	stmt
+	IfExists bool
+}
+
+func (s *dropConnectorStmt) GetActionType() StmtActionType {
+	return s.Type
+}
+
+// GetName is prefixed so that it doesn't clash with a CREATE of the same connector
+func (s *dropConnectorStmt) GetName() string {
+	return fmt.Sprintf("%s %s %s", ReservedDrop, ReservedConnector, s.Name)
+}
+
+func (s *dropConnectorStmt) GetDataSources() []string {
+	return nil
+}
+
+func (s *dropConnectorStmt) String() string {
+	sb := []string{ReservedDrop, ReservedConnector}
+	if s.IfExists {
+		sb = append(sb, ReservedIfExists)
+	}
+	sb = append(sb, s.Name, ReservedEndOfStatement)
+	return strings.Join(sb, " ")
+}
+
+type describeConnectorStmt struct {
+	stmt
+}
+
+func (s *describeConnectorStmt) GetActionType() StmtActionType {
+	return s.Type
+}
+
+// GetName is prefixed so that it doesn't clash with a CREATE of the same connector
+func (s *describeConnectorStmt) GetName() string {
+	return fmt.Sprintf("%s %s %s", ReservedDescribe, ReservedConnector, s.Name)
+}
+
+func (s *describeConnectorStmt) GetDataSources() []string {
+	return nil
+}
+
+func (s *describeConnectorStmt) String() string {
+	return strings.Join([]string{ReservedDescribe, ReservedConnector, s.Name, ReservedEndOfStatement}, " ")
+}
+
+// parseConnectorName pops the name of a connector which may be quoted
+func (p *parser) parseConnectorName() (string, error) {
+	n, l := p.peekQuotedIdentifierWithLength()
+	if l == 0 {
+		n, l = p.peekIdentifierWithLength()
+		if l == 0 || !isIdentifier(n) {
+			return "", p.Error("[name]")
+		}
+	}
+	p.popLength(l)
+	return n, nil
+}
+
+// parseCreateConnector parses the rest of a CREATE SOURCE|SINK CONNECTOR
+func (p *parser) parseCreateConnector(item string, connectorType ConnectorType) (Stmt, error) {
+	result := &createConnectorStmt{
+		stmt:          stmt{Type: StmtActionType(item)},
+		ConnectorType: connectorType,
+	}
+	if s, l := p.peekWithLength(ReservedIfNotExists); s == ReservedIfNotExists {
+		p.popLength(l)
+		result.IfNotExists = true
+	}
+	n, err := p.parseConnectorName()
+	if err != nil {
+		return nil, err
+	}
+	result.Name = n
+	if _, err := p.popOrError(ReservedWith); err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedOpenParens); err != nil {
+		return nil, err
+	}
+	for {
+		key, l := p.peekQuotedIdentifierWithLength()
+		if l == 0 {
+			key, l = p.peekQuotedStringWithLength()
+		}
+		if l == 0 {
+			key, l = p.peekIdentifierWithLength()
+		}
+		if l == 0 {
+			return nil, p.Error("[property]")
+		}
+		p.popLength(l)
+		if _, err := p.popOrError(ReservedEq); err != nil {
+			return nil, err
+		}
+		value, l := p.peekWithLength()
+		if l == 0 {
+			return nil, p.Error("[value]")
+		}
+		p.popLength(l)
+		result.Properties = append(result.Properties, connectorProperty{Key: key, Value: value})
+
+		next, err := p.popOrError(ReservedComma, ReservedCloseParens)
+		if err != nil {
+			return nil, err
+		}
+		if next == ReservedCloseParens {
+			break
+		}
+	}
+	if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseDropConnector parses the rest of a DROP CONNECTOR
+func (p *parser) parseDropConnector() (Stmt, error) {
+	result := &dropConnectorStmt{
+		stmt: stmt{Type: StmtTypeDrop},
+	}
+	if s, l := p.peekWithLength(ReservedIfExists); s == ReservedIfExists {
+		p.popLength(l)
+		result.IfExists = true
+	}
+	n, err := p.parseConnectorName()
+	if err != nil {
+		return nil, err
+	}
+	result.Name = n
+	if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseDescribeConnector parses the rest of a DESCRIBE CONNECTOR
+func (p *parser) parseDescribeConnector() (Stmt, error) {
+	n, err := p.parseConnectorName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+		return nil, err
+	}
+	return &describeConnectorStmt{
+		stmt: stmt{Type: StmtTypeDescribe, Name: n},
+	}, nil
+}
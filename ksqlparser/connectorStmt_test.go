@@ -0,0 +1,88 @@
+package ksqlparser
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestParseConnectors(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want Stmt
+	}{
+		{
+			name: "create source connector",
+			sql: "CREATE SOURCE CONNECTOR `jdbc-source` WITH (\"connector.class\" = 'io.confluent.connect.jdbc.JdbcSourceConnector', " +
+				"'tasks.max' = '1', mode = 'bulk');",
+			want: &createConnectorStmt{
+				stmt:          stmt{Type: StmtTypeCreate, Name: "`jdbc-source`"},
+				ConnectorType: ConnectorTypeSource,
+				Properties: []connectorProperty{
+					{Key: `"connector.class"`, Value: "'io.confluent.connect.jdbc.JdbcSourceConnector'"},
+					{Key: "'tasks.max'", Value: "'1'"},
+					{Key: "mode", Value: "'bulk'"},
+				},
+			},
+		},
+		{
+			name: "create sink connector if not exists",
+			sql:  "create sink connector if not exists elastic with (\"topics\" = 'pages');",
+			want: &createConnectorStmt{
+				stmt:          stmt{Type: StmtTypeCreate, Name: "elastic"},
+				ConnectorType: ConnectorTypeSink,
+				IfNotExists:   true,
+				Properties:    []connectorProperty{{Key: `"topics"`, Value: "'pages'"}},
+			},
+		},
+		{
+			name: "drop connector",
+			sql:  "DROP CONNECTOR IF EXISTS `jdbc-source`;",
+			want: &dropConnectorStmt{
+				stmt:     stmt{Type: StmtTypeDrop, Name: "`jdbc-source`"},
+				IfExists: true,
+			},
+		},
+		{
+			name: "describe connector",
+			sql:  "DESCRIBE CONNECTOR elastic;",
+			want: &describeConnectorStmt{
+				stmt: stmt{Type: StmtTypeDescribe, Name: "elastic"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(stmts, []Stmt{tt.want}); diff != nil {
+				t.Error(diff)
+			}
+			// the string form should parse to the same statement
+			reparsed, err := Parse(stmts[0].String())
+			if err != nil {
+				t.Fatalf("String() output does not parse: %v", err)
+			}
+			if diff := deep.Equal(reparsed, stmts); diff != nil {
+				t.Errorf("String() changed the statement %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseConnectorErrors(t *testing.T) {
+	for _, sql := range []string{
+		"CREATE OR REPLACE SOURCE CONNECTOR foo WITH (a = 'b');",
+		"CREATE SOURCE CONNECTOR foo;",
+		"CREATE SINK CONNECTOR foo WITH (a 'b');",
+		"DROP STREAM foo;",
+		"DESCRIBE CONNECTOR;",
+	} {
+		if _, err := Parse(sql); err == nil {
+			t.Errorf("Parse(%s) expected an error", sql)
+		}
+	}
+}
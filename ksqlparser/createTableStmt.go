@@ -48,6 +48,11 @@ func (s *createTableStmt) GetDataSources() []string {
 	var result []string
 	if s.Select != nil {
 		result = append(result, s.Select.Identifier.Name)
+		if s.Select.Joins != nil {
+			for _, j := range *s.Select.Joins {
+				result = append(result, j.Identifier.Name)
+			}
+		}
 	}
 	return result
 }
@@ -0,0 +1,32 @@
+package ksqlparser
+
+import (
+	"strings"
+)
+
+type createTypeStmt struct {
+	stmt
+	Definition dataTypeDefinition
+}
+
+func (s *createTypeStmt) GetObjectType() CreateObjectType {
+	return CreateObjectTypeType
+}
+
+func (s *createTypeStmt) GetActionType() StmtActionType {
+	return s.Type
+}
+
+func (s *createTypeStmt) String() string {
+	sb := []string{string(s.stmt.Type), ReservedType, s.Name, ReservedAs, s.Definition.String(), ReservedEndOfStatement}
+	return strings.Join(sb, " ")
+}
+
+func (s *createTypeStmt) GetName() string {
+	return s.Name
+}
+
+// GetDataSources returns nil as a type doesn't read from anything, the types it uses are found with Types
+func (s *createTypeStmt) GetDataSources() []string {
+	return nil
+}
@@ -92,7 +92,21 @@ func (s *structField) String() string {
 	return fmt.Sprintf("%s %s", s.Name, s.Type.String())
 }
 
+// customDataType is a reference to a type declared with CREATE TYPE
+type customDataType struct {
+	Name string
+}
+
+func (s *customDataType) String() string {
+	return s.Name
+}
+
 func (p *parser) parseDataType() (dataTypeDefinition, error) {
+	// anything which isn't a built in type is a reference to a custom type
+	if n, l := p.peekIdentifierWithLength(); l > 0 && !isDataType(n) && isIdentifier(n) && !strings.ContainsAny(n, ".*->") {
+		p.popLength(l)
+		return &customDataType{Name: n}, nil
+	}
 	dataType, err := p.popOrError(dataTypes...)
 	if err != nil {
 		return nil, err
@@ -178,5 +192,5 @@ func (p *parser) parseDataType() (dataTypeDefinition, error) {
 			Type: dataType,
 		}, nil
 	}
-	return nil, fmt.Errorf("unhandled DataType %s", dataType)
+	return nil, p.SyntaxError()
 }
@@ -0,0 +1,186 @@
+package ksqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defineStmt defines a variable for the statements which follow it, the value is quoted as written
+type defineStmt struct {
+	stmt
+	Value string
+}
+
+func (s *defineStmt) GetActionType() StmtActionType {
+	return s.Type
+}
+
+// GetName is prefixed so that it doesn't clash with the name of a stream or table
+func (s *defineStmt) GetName() string {
+	return fmt.Sprintf("%s %s", ReservedDefine, s.Name)
+}
+
+func (s *defineStmt) GetDataSources() []string {
+	return nil
+}
+
+func (s *defineStmt) String() string {
+	return strings.Join([]string{ReservedDefine, s.Name, ReservedEq, s.Value + ReservedEndOfStatement}, " ")
+}
+
+// undefineStmt removes a variable for the statements which follow it
+type undefineStmt struct {
+	stmt
+}
+
+func (s *undefineStmt) GetActionType() StmtActionType {
+	return s.Type
+}
+
+// GetName is prefixed so that it doesn't clash with the name of a stream or table
+func (s *undefineStmt) GetName() string {
+	return fmt.Sprintf("%s %s", ReservedUndefine, s.Name)
+}
+
+func (s *undefineStmt) GetDataSources() []string {
+	return nil
+}
+
+func (s *undefineStmt) String() string {
+	return fmt.Sprintf("%s %s%s", ReservedUndefine, s.Name, ReservedEndOfStatement)
+}
+
+// parseVariableName pops the name of a variable
+func (p *parser) parseVariableName() (string, error) {
+	n, l := p.peekIdentifierWithLength()
+	if l == 0 || !isIdentifier(n) {
+		return "", p.Error("[variable]")
+	}
+	p.popLength(l)
+	return n, nil
+}
+
+// parseDefine parses the rest of a DEFINE name = 'value'
+func (p *parser) parseDefine() (Stmt, error) {
+	n, err := p.parseVariableName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedEq); err != nil {
+		return nil, err
+	}
+	v, err := p.parseProperty("[value]")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+		return nil, err
+	}
+	return &defineStmt{
+		stmt:  stmt{Type: StmtTypeDefine, Name: n},
+		Value: v,
+	}, nil
+}
+
+// parseUndefine parses the rest of an UNDEFINE name
+func (p *parser) parseUndefine() (Stmt, error) {
+	n, err := p.parseVariableName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+		return nil, err
+	}
+	return &undefineStmt{
+		stmt: stmt{Type: StmtTypeUndefine, Name: n},
+	}, nil
+}
+
+// Substitute replaces each ${name} in sql with the value of the variable name, variables aren't substituted in
+// comments. Variables start as variables and each DEFINE or UNDEFINE changes them for the statements which follow it,
+// those statements are kept in the result. A variable which isn't defined is an error.
+func Substitute(sql string, variables map[string]string) (string, error) {
+	current := variables
+	var sb strings.Builder
+	start := 0
+	for _, end := range append(statementEnds(sql), len(sql)) {
+		substituted, err := substitute(sql[start:end], current)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(substituted)
+		if end < len(sql) {
+			sb.WriteString(ReservedEndOfStatement)
+		}
+		start = end + len(ReservedEndOfStatement)
+
+		p := &parser{sql: substituted + ReservedEndOfStatement}
+		p.popWhitespace()
+		if s, _ := p.peekWithLength(ReservedDefine, ReservedUndefine); s != ReservedDefine && s != ReservedUndefine {
+			continue
+		}
+		stmt, err := p.doParse()
+		if err != nil {
+			// it'll be reported when the result is parsed
+			continue
+		}
+		current = copyProperties(current)
+		switch stmt := stmt.(type) {
+		case *defineStmt:
+			current[stmt.Name] = unquoteProperty(stmt.Value)
+		case *undefineStmt:
+			delete(current, stmt.Name)
+		}
+	}
+	return sb.String(), nil
+}
+
+// substitute replaces each ${name} outside of comments in a single statement, strings and quoted identifiers
+// are substituted too
+func substitute(sql string, variables map[string]string) (string, error) {
+	var sb strings.Builder
+	start := 0
+	for i := 0; i < len(sql); {
+		end := skipQuotedOrComment(sql, i)
+		if end == i || sql[i] == '\'' || sql[i] == '"' || sql[i] == '`' {
+			i = max(end, i+1)
+			continue
+		}
+		// copy the comment as it is
+		replaced, err := replaceVariables(sql[start:i], variables)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(replaced + sql[i:end])
+		start, i = end, end
+	}
+	replaced, err := replaceVariables(sql[start:], variables)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(replaced)
+	return sb.String(), nil
+}
+
+// replaceVariables replaces each ${name} in s
+func replaceVariables(s string, variables map[string]string) (string, error) {
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		end := strings.Index(s[i:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable %s", s[i:])
+		}
+		name := s[i+2 : i+end]
+		value, ok := variables[name]
+		if !ok {
+			return "", fmt.Errorf("variable %s is not defined", name)
+		}
+		sb.WriteString(s[:i] + value)
+		s = s[i+end+1:]
+	}
+}
@@ -0,0 +1,116 @@
+package ksqlparser
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestParseDefine(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want Stmt
+	}{
+		{
+			name: "define",
+			sql:  "DEFINE env = 'prod';",
+			want: &defineStmt{
+				stmt:  stmt{Type: StmtTypeDefine, Name: "env"},
+				Value: "'prod'",
+			},
+		},
+		{
+			name: "undefine",
+			sql:  "undefine env;",
+			want: &undefineStmt{
+				stmt: stmt{Type: StmtTypeUndefine, Name: "env"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(stmts, []Stmt{tt.want}); diff != nil {
+				t.Error(diff)
+			}
+			reparsed, err := Parse(stmts[0].String())
+			if err != nil {
+				t.Fatalf("String() output does not parse: %v", err)
+			}
+			if diff := deep.Equal(reparsed, stmts); diff != nil {
+				t.Errorf("String() changed the statement %v", diff)
+			}
+		})
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		variables map[string]string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "identifiers and strings",
+			sql:       "CREATE STREAM ${env}_pageviews WITH (kafka_topic='${env}.pageviews');",
+			variables: map[string]string{"env": "prod"},
+			want:      "CREATE STREAM prod_pageviews WITH (kafka_topic='prod.pageviews');",
+		},
+		{
+			name:      "define",
+			sql:       "DEFINE env = 'stage';\nCREATE STREAM ${env}_a AS SELECT * FROM b;",
+			variables: map[string]string{"env": "prod"},
+			want:      "DEFINE env = 'stage';\nCREATE STREAM stage_a AS SELECT * FROM b;",
+		},
+		{
+			name:      "undefine",
+			sql:       "UNDEFINE env;\nCREATE STREAM ${env}_a AS SELECT * FROM b;",
+			variables: map[string]string{"env": "prod"},
+			wantErr:   true,
+		},
+		{
+			name:      "comments are left alone",
+			sql:       "-- uses ${env}\nCREATE STREAM ${env}_a /* ${x} */ AS SELECT * FROM b;",
+			variables: map[string]string{"env": "dev"},
+			want:      "-- uses ${env}\nCREATE STREAM dev_a /* ${x} */ AS SELECT * FROM b;",
+		},
+		{
+			name:    "undefined",
+			sql:     "CREATE STREAM ${env}_a AS SELECT * FROM b;",
+			wantErr: true,
+		},
+		{
+			name:      "unterminated",
+			sql:       "CREATE STREAM ${env_a AS SELECT * FROM b;",
+			variables: map[string]string{"env": "dev"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Substitute(tt.sql, tt.variables)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Substitute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Substitute() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnsubstituted(t *testing.T) {
+	stmts, err := Parse("CREATE STREAM ${env}_a AS SELECT * FROM ${env}_b;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(stmts[0].GetDataSources(), []string{"${env}_b"}); diff != nil {
+		t.Error(diff)
+	}
+}
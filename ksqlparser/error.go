@@ -1,11 +1,110 @@
 package ksqlparser
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError describes where and why a statement could not be parsed
+type ParseError struct {
+	// Statement is the index of the statement which failed to parse
+	Statement int
+	// Line is the line of the error in the source starting at 1
+	Line int
+	// Column is the column of the error in the source starting at 1
+	Column int
+	// Expected describes what the parser was looking for
+	Expected string
+	// Found is the text found at the error
+	Found string
+	// Snippet is the source line containing the error followed by a line with a caret under the error
+	Snippet string
+
+	// offset is the position of the error in the sql being parsed
+	offset int
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("error parsing statement %d at line %d col %d: ", e.Statement, e.Line, e.Column)
+	if e.Expected == "" {
+		msg += fmt.Sprintf("syntax error at %s", e.Found)
+	} else {
+		msg += fmt.Sprintf("expected %s but found %s", e.Expected, e.Found)
+	}
+	if e.Snippet != "" {
+		msg += "\n" + e.Snippet
+	}
+	return msg
+}
+
+// ParseErrors are all of the errors found when parsing
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// locate sets the position of the error given that the sql being parsed started at offset in source
+func (e *ParseError) locate(source string, offset int, statement int) {
+	e.Statement = statement
+	pos := min(len(source), offset+e.offset)
+
+	lineStart := strings.LastIndex(source[:pos], "\n") + 1
+	lineEnd := strings.Index(source[pos:], "\n")
+	if lineEnd < 0 {
+		lineEnd = len(source)
+	} else {
+		lineEnd += pos
+	}
+	e.Line = strings.Count(source[:lineStart], "\n") + 1
+	e.Column = utf8.RuneCountInString(source[lineStart:pos]) + 1
+
+	// keep tabs in the caret line so that the caret lines up with the source
+	var caret strings.Builder
+	for _, r := range source[lineStart:pos] {
+		if r == '\t' {
+			caret.WriteRune('\t')
+			continue
+		}
+		caret.WriteRune(' ')
+	}
+	number := fmt.Sprintf("%d", e.Line)
+	e.Snippet = fmt.Sprintf("%s | %s\n%s | %s^",
+		number, strings.TrimRight(source[lineStart:lineEnd], "\r"), strings.Repeat(" ", len(number)), caret.String())
+}
 
 func (p *parser) Error(expected string) error {
-	return fmt.Errorf("expected %s at line %d col %d, %s^", expected, p.line, p.col, p.sql[:p.i])
+	return &ParseError{
+		Expected: expected,
+		Found:    p.found(),
+		Line:     p.line + 1,
+		Column:   p.col + 1,
+		offset:   p.i,
+	}
 }
 
 func (p *parser) SyntaxError() error {
-	return fmt.Errorf("syntax error at line %d col %d, %s^", p.line, p.col, p.sql[:p.i])
+	return p.Error("")
+}
+
+// found describes the token at the current position for use in errors
+func (p *parser) found() string {
+	if p.i >= len(p.sql) {
+		return "end of input"
+	}
+	if p.sql[p.i:] == ReservedEndOfStatement {
+		return "end of statement"
+	}
+	if token, l := p.peekWithLength(); l > 0 {
+		if strings.HasPrefix(token, "'") {
+			return token
+		}
+		return fmt.Sprintf("'%s'", token)
+	}
+	return fmt.Sprintf("'%c'", p.sql[p.i])
 }
@@ -0,0 +1,127 @@
+package ksqlparser
+
+import (
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want ParseError
+	}{
+		{
+			name: "error in the first statement",
+			sql:  "CREATE STREAM foo AS SELECT a FROM;",
+			want: ParseError{
+				Statement: 0,
+				Line:      1,
+				Column:    35,
+				Expected:  "[identifier]",
+				Found:     "end of statement",
+				Snippet: "1 | CREATE STREAM foo AS SELECT a FROM;\n" +
+					"  |                                   ^",
+			},
+		},
+		{
+			name: "error after comments in a later statement",
+			sql: `
+CREATE STREAM foo (a STRING) WITH (KAFKA_TOPIC='foo');
+
+-- comments are counted
+-- when working out the line
+CREATE TABLE bar AS
+	SELECT a FROM foo
+	WINDOW TUMBLING (SIZE 1 DAYS)
+	EMIT CHANGES;`,
+			want: ParseError{
+				Statement: 1,
+				Line:      8,
+				Column:    26,
+				Expected:  "[SECONDS, MINUTES, HOURS, SECOND, MINUTE, HOUR]",
+				Found:     "'DAYS'",
+				Snippet: "8 | \tWINDOW TUMBLING (SIZE 1 DAYS)\n" +
+					"  | \t                        ^",
+			},
+		},
+		{
+			name: "unknown statement",
+			sql:  "garbage here;",
+			want: ParseError{
+				Line:     1,
+				Column:   1,
+				Expected: "CREATE OR REPLACE or CREATE or REPLACE or INSERT INTO or DROP or DESCRIBE or SET or UNSET or DEFINE or UNDEFINE",
+				Found:    "'garbage'",
+				Snippet: "1 | garbage here;\n" +
+					"  | ^",
+			},
+		},
+		{
+			name: "misspelt kind",
+			sql:  "CREATE STRAEM foo AS SELECT a FROM bar;",
+			want: ParseError{
+				Line:     1,
+				Column:   8,
+				Expected: "TABLE or STREAM or TYPE or SOURCE CONNECTOR or SINK CONNECTOR",
+				Found:    "'STRAEM'",
+				Snippet: "1 | CREATE STRAEM foo AS SELECT a FROM bar;\n" +
+					"  |        ^",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.sql)
+			errs, ok := err.(ParseErrors)
+			if !ok || len(errs) != 1 {
+				t.Fatalf("Parse() error = %v, want a single ParseError", err)
+			}
+			perr := errs[0]
+			perr.offset = 0
+			if *perr != tt.want {
+				t.Errorf("Parse() error = %#v, want %#v", *perr, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorRecovery(t *testing.T) {
+	sql := `CREATE STREAM foo (a STRING) WITH (KAFKA_TOPIC='foo;bar');
+CREATE STREAM bar AS SELECT a FROM;
+-- a missing ; is recovered at the next statement
+CREATE TABLE baz AS SELECT a, COUNT(*) FROM foo GROUP BY a EMIT
+CREATE STREAM qux AS SELECT a FROM foo EMIT CHANGES;
+INSERT INTO qux SELECT a FROM foo WHERE a = 'CREATE';
+CREATE STREAM quux AS SELECT a FROM foo WINDOW;`
+	stmts, err := Parse(sql)
+	errs, ok := err.(ParseErrors)
+	if !ok {
+		t.Fatalf("Parse() error = %v, want ParseErrors", err)
+	}
+
+	var names []string
+	for _, stmt := range stmts {
+		names = append(names, stmt.GetName())
+	}
+	if len(names) != 3 || names[0] != "foo" || names[1] != "qux" {
+		t.Errorf("Parse() got statements %v, want foo, qux and an insert", names)
+	}
+
+	want := []struct {
+		statement int
+		line      int
+	}{
+		{statement: 1, line: 2},
+		{statement: 2, line: 5},
+		{statement: 5, line: 7},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Parse() got %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if errs[i].Statement != w.statement || errs[i].Line != w.line {
+			t.Errorf("Parse() error %d at statement %d line %d, want statement %d line %d",
+				i, errs[i].Statement, errs[i].Line, w.statement, w.line)
+		}
+	}
+}
@@ -13,6 +13,12 @@ func (p *parser) parseExpressionList(endKeywords ...string) (*[]*aliasedExpressi
 	var result []*aliasedExpression
 
 	for {
+		if len(result) > 0 {
+			// a comment after the comma belongs to the previous expression
+			prev := result[len(result)-1]
+			prev.Comments.Trailing = append(prev.Comments.Trailing, p.trailingComments()...)
+		}
+		leading := p.leadingComments()
 		e, err := p.parseExpression()
 		if err != nil {
 			return nil, err
@@ -20,6 +26,7 @@ func (p *parser) parseExpressionList(endKeywords ...string) (*[]*aliasedExpressi
 		expr := &aliasedExpression{
 			Expression: e,
 			Alias:      "",
+			Comments:   Comments{Leading: leading},
 		}
 
 		item, l := p.peekWithLength(append(endKeywords, ReservedAs, ReservedComma)...)
@@ -33,6 +40,7 @@ func (p *parser) parseExpressionList(endKeywords ...string) (*[]*aliasedExpressi
 			}
 
 			if arrayContains(endKeywords, strings.ToUpper(item)) {
+				expr.Comments.Trailing = p.trailingComments()
 				return &result, nil // done parsing expressions don't pop so the keyword remains
 			}
 
@@ -53,6 +61,7 @@ func (p *parser) parseExpressionList(endKeywords ...string) (*[]*aliasedExpressi
 		}
 
 		if item == ReservedComma {
+			expr.Comments.Trailing = p.trailingComments()
 			p.popLength(l)
 			continue
 		}
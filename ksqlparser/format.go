@@ -0,0 +1,437 @@
+package ksqlparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeywordCase is the case keywords are written in by Format
+type KeywordCase string
+
+const (
+	KeywordCaseUpper = KeywordCase("upper")
+	KeywordCaseLower = KeywordCase("lower")
+)
+
+// FormatOptions controls the layout produced by Format
+type FormatOptions struct {
+	// IndentWidth is the number of spaces nested items are indented by
+	IndentWidth int
+	// KeywordCase is the case keywords, data types and WITH properties are written in
+	KeywordCase KeywordCase
+	// OneSelectItemPerLine writes each select expression on its own line
+	OneSelectItemPerLine bool
+	// AlignWith writes each WITH property on its own line with the = signs aligned
+	AlignWith bool
+	// KeepComments writes out the comments found in the source
+	KeepComments bool
+}
+
+// DefaultFormatOptions are the options used by ksqlfmt when no flags are given
+var DefaultFormatOptions = FormatOptions{
+	IndentWidth:          2,
+	KeywordCase:          KeywordCaseUpper,
+	OneSelectItemPerLine: true,
+	AlignWith:            true,
+	KeepComments:         true,
+}
+
+// Format writes stmts out as ksql laid out according to opts.
+// Unlike String, which is used for hashing and naming, the output of Format is intended to be read by people.
+func Format(stmts []Stmt, opts FormatOptions) string {
+	f := &formatter{opts: opts}
+	var sb []string
+	for _, s := range stmts {
+		sb = append(sb, f.stmt(s))
+	}
+	if len(sb) == 0 {
+		return ""
+	}
+	return strings.Join(sb, "\n\n") + "\n"
+}
+
+type formatter struct {
+	opts FormatOptions
+}
+
+// kw applies the keyword case to each of the words and joins them with a space
+func (f *formatter) kw(words ...string) string {
+	s := strings.Join(words, " ")
+	if f.opts.KeywordCase == KeywordCaseLower {
+		return strings.ToLower(s)
+	}
+	return strings.ToUpper(s)
+}
+
+func (f *formatter) indent() string {
+	return strings.Repeat(" ", f.opts.IndentWidth)
+}
+
+// leading writes each of the leading comments on its own line
+func (f *formatter) leading(c Comments, indent string) string {
+	if !f.opts.KeepComments {
+		return ""
+	}
+	var sb strings.Builder
+	for _, l := range c.Leading {
+		sb.WriteString(indent + l + "\n")
+	}
+	return sb.String()
+}
+
+// trailing writes the trailing comments, lineEnds should only be true if a newline will be written next
+func (f *formatter) trailing(c Comments, lineEnds bool) string {
+	if !f.opts.KeepComments {
+		return ""
+	}
+	var sb strings.Builder
+	for i, t := range c.Trailing {
+		if !lineEnds || i < len(c.Trailing)-1 {
+			t = inlineComment(t)
+		}
+		sb.WriteString(" " + t)
+	}
+	return sb.String()
+}
+
+// inlineLeading writes the leading comments for a node which isn't at the start of a line
+func (f *formatter) inlineLeading(c Comments) string {
+	if !f.opts.KeepComments {
+		return ""
+	}
+	var sb strings.Builder
+	for _, l := range c.Leading {
+		sb.WriteString(inlineComment(l) + " ")
+	}
+	return sb.String()
+}
+
+// inlineComment turns a -- comment into a /* */ comment so that it can be followed by more ksql on the same line
+func inlineComment(c string) string {
+	if strings.HasPrefix(c, "--") {
+		return "/* " + strings.TrimSpace(strings.TrimPrefix(c, "--")) + " */"
+	}
+	return c
+}
+
+func (f *formatter) stmt(s Stmt) string {
+	var sb strings.Builder
+	c, ok := s.(commented)
+	if !ok {
+		return s.String()
+	}
+	sb.WriteString(f.leading(*c.comments(), ""))
+	switch s := s.(type) {
+	case *createStreamStmt:
+		sb.WriteString(f.create(s.stmt, ReservedStream, s.Columns, s.With))
+		if s.Select != nil {
+			sb.WriteString(" " + f.kw(ReservedAs) + "\n" + f.streamSelect(s.Select))
+		}
+		if s.EmitChanges {
+			sb.WriteString("\n" + f.kw(ReservedEmit))
+		}
+	case *createTableStmt:
+		sb.WriteString(f.create(s.stmt, ReservedTable, s.Columns, s.With))
+		if s.Select != nil {
+			sb.WriteString(" " + f.kw(ReservedAs) + "\n" + f.tableSelect(s.Select))
+		}
+		if s.EmitChanges {
+			sb.WriteString("\n" + f.kw(ReservedEmit))
+		}
+	case *insertIntoStmt:
+		sb.WriteString(f.kw(string(s.Type)) + " " + s.Name + "\n" + f.streamSelect(s.Select))
+	case *insertValuesStmt:
+		sb.WriteString(f.kw(string(s.Type)) + " " + s.Name)
+		if len(s.Columns) > 0 {
+			sb.WriteString(" " + ReservedOpenParens + strings.Join(s.Columns, ReservedComma+" ") + ReservedCloseParens)
+		}
+		var values []string
+		for _, v := range s.Values {
+			values = append(values, f.expression(v))
+		}
+		sb.WriteString(" " + f.kw(ReservedValues) + " " + ReservedOpenParens + strings.Join(values, ReservedComma+" ") + ReservedCloseParens)
+	case *createTypeStmt:
+		sb.WriteString(f.kw(string(s.Type), ReservedType) + " " + s.Name + " " + f.kw(ReservedAs) + " " + f.dataType(s.Definition))
+	case *createConnectorStmt:
+		sb.WriteString(f.kw(string(s.Type), string(s.ConnectorType), ReservedConnector))
+		if s.IfNotExists {
+			sb.WriteString(" " + f.kw(ReservedIfNotExists))
+		}
+		var names, values []string
+		for _, prop := range s.Properties {
+			names, values = append(names, prop.Key), append(values, prop.Value)
+		}
+		sb.WriteString(" " + s.Name + " " + f.kw(ReservedWith) + " " + f.properties(names, values))
+	case *dropConnectorStmt:
+		sb.WriteString(f.kw(ReservedDrop, ReservedConnector))
+		if s.IfExists {
+			sb.WriteString(" " + f.kw(ReservedIfExists))
+		}
+		sb.WriteString(" " + s.Name)
+	case *describeConnectorStmt:
+		sb.WriteString(f.kw(ReservedDescribe, ReservedConnector) + " " + s.Name)
+	case *setStmt:
+		sb.WriteString(f.kw(ReservedSet) + " " + s.Name + ReservedEq + s.Value)
+	case *unsetStmt:
+		sb.WriteString(f.kw(ReservedUnset) + " " + s.Name)
+	case *defineStmt:
+		sb.WriteString(f.kw(ReservedDefine) + " " + s.Name + " " + ReservedEq + " " + s.Value)
+	case *undefineStmt:
+		sb.WriteString(f.kw(ReservedUndefine) + " " + s.Name)
+	default:
+		return s.String()
+	}
+	sb.WriteString(ReservedEndOfStatement)
+	sb.WriteString(f.trailing(*c.comments(), true))
+	return sb.String()
+}
+
+func (f *formatter) create(s stmt, objectType string, columns *columnDefinitions, w *with) string {
+	sb := f.kw(string(s.Type), objectType) + " " + s.Name
+	if columns != nil {
+		var cols []string
+		for i, c := range *columns {
+			col := f.leading(c.Comments, f.indent()) + f.indent() + f.column(c)
+			if i < len(*columns)-1 {
+				col += ReservedComma
+			}
+			cols = append(cols, col+f.trailing(c.Comments, true))
+		}
+		sb += " " + ReservedOpenParens + "\n" + strings.Join(cols, "\n") + "\n" + ReservedCloseParens
+	}
+	if w != nil {
+		sb += " " + f.kw(ReservedWith) + " " + f.with(w)
+	}
+	return sb
+}
+
+func (f *formatter) column(c columnDefinition) string {
+	sb := c.Name + " " + f.dataType(c.DataType)
+	if c.IsKey {
+		sb += " " + f.kw(ReservedKey)
+	}
+	if c.IsPrimary {
+		sb += " " + f.kw(ReservedPrimaryKey)
+	}
+	return sb
+}
+
+func (f *formatter) with(w *with) string {
+	var names, values []string
+	add := func(name, value string) {
+		names = append(names, f.kw(name))
+		values = append(values, value)
+	}
+	if w.KafkaTopic != "" {
+		add(WithPropertyKafkaTopic, w.KafkaTopic)
+	}
+	if w.ValueFormat != "" {
+		add(WithPropertyValueFormat, string(w.ValueFormat))
+	}
+	if w.Key != "" {
+		add(WithPropertyKey, w.Key)
+	}
+	if w.TimeStamp != "" {
+		add(WithPropertyTimeStamp, w.TimeStamp)
+	}
+	if w.Replicas > 0 {
+		add(WithPropertyReplicas, strconv.Itoa(w.Replicas))
+	}
+	if w.Partitions > 0 {
+		add(WithPropertyPartitions, strconv.Itoa(w.Partitions))
+	}
+	return f.properties(names, values)
+}
+
+// properties writes the parenthesised name = value pairs of a WITH
+func (f *formatter) properties(names, values []string) string {
+	if !f.opts.AlignWith {
+		var props []string
+		for i := range names {
+			props = append(props, fmt.Sprintf("%s %s %s", names[i], ReservedEq, values[i]))
+		}
+		return ReservedOpenParens + strings.Join(props, ReservedComma+" ") + ReservedCloseParens
+	}
+
+	width := 0
+	for _, n := range names {
+		if len(n) > width {
+			width = len(n)
+		}
+	}
+	var props []string
+	for i := range names {
+		props = append(props, fmt.Sprintf("%s%-*s %s %s", f.indent(), width, names[i], ReservedEq, values[i]))
+	}
+	return ReservedOpenParens + "\n" + strings.Join(props, ReservedComma+"\n") + "\n" + ReservedCloseParens
+}
+
+func (f *formatter) selectItems(exprs aliasedExpressions) string {
+	var items []string
+	if f.opts.OneSelectItemPerLine {
+		for i, e := range exprs {
+			item := f.leading(e.Comments, f.indent()) + f.indent() + f.aliasedExpression(e)
+			if i < len(exprs)-1 {
+				item += ReservedComma
+			}
+			items = append(items, item+f.trailing(e.Comments, true))
+		}
+		return f.kw(ReservedSelect) + "\n" + strings.Join(items, "\n")
+	}
+	for _, e := range exprs {
+		items = append(items, f.inlineLeading(e.Comments)+f.aliasedExpression(e)+f.trailing(e.Comments, false))
+	}
+	return f.kw(ReservedSelect) + " " + strings.Join(items, ReservedComma+" ")
+}
+
+func (f *formatter) streamSelect(s *streamSelect) string {
+	sb := []string{
+		f.selectItems(s.Expressions),
+		f.kw(ReservedFrom) + " " + f.identifier(s.Identifier),
+	}
+	sb = append(sb, f.joins(s.Joins)...)
+	if s.Where != nil {
+		sb = append(sb, f.kw(ReservedWhere)+" "+f.conditions(*s.Where, true))
+	}
+	if s.Partition != "" {
+		sb = append(sb, f.kw(ReservedPartitionBy)+" "+s.Partition)
+	}
+	return strings.Join(sb, "\n")
+}
+
+func (f *formatter) joins(joins *[]joinExpression) []string {
+	var sb []string
+	if joins != nil {
+		for _, j := range *joins {
+			sb = append(sb, f.kw(ReservedLeftJoin)+" "+f.identifier(j.Identifier)+" "+f.kw(ReservedOn)+" "+f.conditions(j.Conditions, false))
+		}
+	}
+	return sb
+}
+
+func (f *formatter) tableSelect(s *tableSelect) string {
+	sb := []string{
+		f.selectItems(s.Expressions),
+		f.kw(ReservedFrom) + " " + f.identifier(s.Identifier),
+	}
+	sb = append(sb, f.joins(s.Joins)...)
+	if s.Window != nil {
+		sb = append(sb, f.kw(ReservedWindow)+" "+f.window(s.Window))
+	}
+	if s.Where != nil {
+		sb = append(sb, f.kw(ReservedWhere)+" "+f.conditions(*s.Where, true))
+	}
+	if s.Group != nil {
+		var group []string
+		for _, e := range s.Group {
+			group = append(group, f.inlineLeading(e.Comments)+f.aliasedExpression(e)+f.trailing(e.Comments, false))
+		}
+		sb = append(sb, f.kw(ReservedGroupBy)+" "+strings.Join(group, ReservedComma+" "))
+	}
+	if s.Having != nil {
+		sb = append(sb, f.kw(ReservedHaving)+" "+f.conditions(*s.Having, true))
+	}
+	return strings.Join(sb, "\n")
+}
+
+func (f *formatter) identifier(i identifier) string {
+	if i.Alias != "" {
+		return i.Name + " " + f.kw(ReservedAs) + " " + i.Alias
+	}
+	return i.Name
+}
+
+// conditions writes out the conditions, when multiline is true each conjunction starts a new line
+func (f *formatter) conditions(conditions []*Condition, multiline bool) string {
+	var sb strings.Builder
+	for i, c := range conditions {
+		if i > 0 {
+			if multiline {
+				sb.WriteString("\n" + f.leading(c.Comments, f.indent()) + f.indent())
+			} else {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(f.kw(conditions[i-1].Conjunction) + " ")
+		}
+		if i == 0 || !multiline {
+			sb.WriteString(f.inlineLeading(c.Comments))
+		}
+		sb.WriteString(f.expression(c.Operand1) + " " + f.kw(string(c.Operator)) + " " + f.expression(c.Operand2))
+		sb.WriteString(f.trailing(c.Comments, multiline && i < len(conditions)-1))
+	}
+	return sb.String()
+}
+
+func (f *formatter) aliasedExpression(e *aliasedExpression) string {
+	if e.Alias != "" {
+		return f.expression(e.Expression) + " " + f.kw(ReservedAs) + " " + e.Alias
+	}
+	return f.expression(e.Expression)
+}
+
+func (f *formatter) expression(e Expression) string {
+	switch e := e.(type) {
+	case *caseWhenExpression:
+		sb := []string{f.kw(ReservedCaseWhen), f.conditions(e.When, false), f.kw(ReservedThen), f.expression(e.Then)}
+		if e.Else != nil {
+			sb = append(sb, f.kw(ReservedElse), f.expression(e.Else))
+		}
+		return strings.Join(append(sb, f.kw(ReservedEnd)), " ")
+	case *castExpression:
+		return fmt.Sprintf("%s%s%s %s %s%s", f.kw(FunctionCast), ReservedOpenParens,
+			f.expression(e.InnerExpression), f.kw(ReservedAs), f.dataType(e.DataType), ReservedCloseParens)
+	case *functionExpression:
+		var params []string
+		for _, p := range e.Params {
+			params = append(params, f.expression(p))
+		}
+		return e.Name + ReservedOpenParens + strings.Join(params, ReservedComma+" ") + ReservedCloseParens
+	case *indexExpression:
+		return f.expression(e.Expression) + ReservedOpenCrotchet + f.expression(e.Index) + ReservedCloseCrotchet
+	case *operatorExpression:
+		return f.expression(e.LeftExpression) + " " + e.Operator + " " + f.expression(e.RightExpression)
+	case nil:
+		return ""
+	default:
+		return e.String()
+	}
+}
+
+func (f *formatter) window(w *WindowExpression) string {
+	var fields []string
+	size := strconv.Itoa(w.Size) + " " + f.kw(w.SizeType)
+	if w.Type != WindowTypeSession {
+		size = f.kw(WindowFieldSize) + " " + size
+	}
+	fields = append(fields, size)
+	if w.AdvanceType != "" {
+		fields = append(fields, f.kw(WindowFieldAdvanceBy)+" "+strconv.Itoa(w.Advance)+" "+f.kw(w.AdvanceType))
+	}
+	if w.RetentionType != "" {
+		fields = append(fields, f.kw(WindowFieldRetention)+" "+strconv.Itoa(w.Retention)+" "+f.kw(w.RetentionType))
+	}
+	if w.GracePeriodType != "" {
+		fields = append(fields, f.kw(WindowFieldGracePeriod)+" "+strconv.Itoa(w.GracePeriod)+" "+f.kw(w.GracePeriodType))
+	}
+	return f.kw(w.Type) + " " + ReservedOpenParens + strings.Join(fields, ReservedComma+" ") + ReservedCloseParens
+}
+
+func (f *formatter) dataType(d dataTypeDefinition) string {
+	switch d := d.(type) {
+	case *simpleDataType:
+		return f.kw(d.Type)
+	case *arrayTypeDataType:
+		return f.kw(DataTypeArray) + ReservedLt + f.dataType(d.ItemType) + ReservedGt
+	case *mapTypeDataType:
+		return f.kw(DataTypeMap) + ReservedLt + f.dataType(d.KeyType) + ReservedComma + " " + f.dataType(d.ValueType) + ReservedGt
+	case *structTypeDataType:
+		var fields []string
+		for _, field := range d.Fields {
+			fields = append(fields, field.Name+" "+f.dataType(field.Type))
+		}
+		return f.kw(DataTypeStruct) + ReservedLt + strings.Join(fields, ReservedComma+" ") + ReservedGt
+	default:
+		return d.String()
+	}
+}
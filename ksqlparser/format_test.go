@@ -0,0 +1,134 @@
+package ksqlparser
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		opts FormatOptions
+		want string
+	}{
+		{
+			name: "create stream with columns and aligned with",
+			sql:  "-- page events\ncreate stream foo (a string, b array<string>) with (kafka_topic='foo', value_format='JSON', partitions=1);",
+			opts: DefaultFormatOptions,
+			want: `-- page events
+CREATE STREAM foo (
+  a STRING,
+  b ARRAY<STRING>
+) WITH (
+  KAFKA_TOPIC  = 'foo',
+  VALUE_FORMAT = 'JSON',
+  PARTITIONS   = 1
+);
+`,
+		},
+		{
+			name: "create table as select with lower case keywords",
+			sql: "CREATE TABLE foo AS SELECT a, COUNT(*) AS total FROM bar WINDOW TUMBLING (SIZE 1 MINUTE) " +
+				"WHERE a != 'x' AND b IS NOT NULL GROUP BY a EMIT CHANGES;",
+			opts: FormatOptions{
+				IndentWidth:          4,
+				KeywordCase:          KeywordCaseLower,
+				OneSelectItemPerLine: true,
+			},
+			want: `create table foo as
+select
+    a,
+    COUNT(*) as total
+from bar
+window tumbling (size 1 minute)
+where a != 'x'
+    and b is not NULL
+group by a
+emit changes;
+`,
+		},
+		{
+			name: "insert into without comments on a single line",
+			sql:  "/* copy */ INSERT INTO foo SELECT a, b FROM bar LEFT JOIN baz ON bar.a = baz.a EMIT CHANGES;",
+			opts: FormatOptions{
+				IndentWidth: 2,
+				KeywordCase: KeywordCaseUpper,
+			},
+			want: `INSERT INTO foo
+SELECT a, b
+FROM bar
+LEFT JOIN baz ON bar.a = baz.a;
+`,
+		},
+		{
+			name: "create type",
+			sql:  "create type address as struct<street string, zip array<postcode>>;",
+			opts: DefaultFormatOptions,
+			want: `CREATE TYPE address AS STRUCT<street STRING, zip ARRAY<postcode>>;
+`,
+		},
+		{
+			name: "insert values",
+			sql:  "insert into currencies (code, name) values ('GBP', 'Pound sterling');",
+			opts: DefaultFormatOptions,
+			want: `INSERT INTO currencies (code, name) VALUES ('GBP', 'Pound sterling');
+`,
+		},
+		{
+			name: "create connector with aligned properties",
+			sql:  "create source connector `jdbc-source` with (\"connector.class\"='JdbcSourceConnector', \"tasks.max\"='1');",
+			opts: DefaultFormatOptions,
+			want: `CREATE SOURCE CONNECTOR ` + "`jdbc-source`" + ` WITH (
+  "connector.class" = 'JdbcSourceConnector',
+  "tasks.max"       = '1'
+);
+`,
+		},
+		{
+			name: "set",
+			sql:  "set 'auto.offset.reset' = 'earliest';",
+			opts: DefaultFormatOptions,
+			want: `SET 'auto.offset.reset'='earliest';
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := Format(stmts, tt.opts)
+			if got != tt.want {
+				t.Errorf("Format() got = \n%s\nwant\n%s", got, tt.want)
+			}
+			// the formatted output should parse to the same statements
+			reparsed, err := Parse(got)
+			if err != nil {
+				t.Fatalf("Format() output does not parse: %v", err)
+			}
+			if Normalise(reparsed[0].String()) != Normalise(stmts[0].String()) {
+				t.Errorf("Format() changed the statement got = %s, want %s", reparsed[0].String(), stmts[0].String())
+			}
+		})
+	}
+}
+
+func TestFormatSamples(t *testing.T) {
+	stmts, err := Parse(ksql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := Parse(Format(stmts, DefaultFormatOptions))
+	if err != nil {
+		t.Fatalf("Format() output does not parse: %v", err)
+	}
+	if len(reparsed) != len(stmts) {
+		t.Fatalf("Format() got %d stmts, want %d", len(reparsed), len(stmts))
+	}
+	for i := range stmts {
+		if Normalise(reparsed[i].String()) != Normalise(stmts[i].String()) {
+			t.Errorf("Format() changed the statement got = %s, want %s", reparsed[i].String(), stmts[i].String())
+		}
+	}
+}
@@ -1,65 +1,314 @@
 package ksqlparser
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 )
 
+// SourceExists reports whether a data source which isn't created by any of the statements exists elsewhere,
+// typically on the ksqlDB server
+type SourceExists func(name string) (bool, error)
+
+// DependencyError describes why statements could not be put in dependency order
+type DependencyError struct {
+	// Cycles are the statement names in each dependency cycle, each statement depends on the one after it and the
+	// last depends on the first
+	Cycles [][]string
+	// Duplicates are the names declared by more than one statement
+	Duplicates []string
+	// Undefined are the sources used by each statement which aren't declared or known to exist
+	Undefined map[string][]string
+}
+
+func (e *DependencyError) Error() string {
+	var msgs []string
+	for _, cycle := range e.Cycles {
+		path := append(append([]string{}, cycle...), cycle[0])
+		msgs = append(msgs, fmt.Sprintf("dependency cycle %s", strings.Join(path, " -> ")))
+	}
+	for _, name := range e.Duplicates {
+		msgs = append(msgs, fmt.Sprintf("%s is declared more than once", name))
+	}
+	var names []string
+	for name := range e.Undefined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s uses undefined sources %s", name, strings.Join(e.Undefined[name], ", ")))
+	}
+	return strings.Join(msgs, "\n")
+}
+
 type graphitem struct {
 	name       string
 	stmt       Stmt
-	depends    map[string]graphitem
-	dependants map[string]graphitem
+	depends    map[string]bool
+	dependants []string
 }
 
-type dependencyGraph []graphitem
+// Dependencies are the names of everything stmt needs to exist before it can be run, the sources it reads, the
+// custom types it uses and, for an insert, the stream it writes to last
+func Dependencies(stmt Stmt) []string {
+	result := append(stmt.GetDataSources(), Types(stmt)...)
+	switch insert := stmt.(type) {
+	case *insertIntoStmt:
+		result = append(result, insert.Name)
+	case *insertValuesStmt:
+		result = append(result, insert.Name)
+	}
+	return result
+}
 
-func (a dependencyGraph) Len() int           { return len(a) }
-func (a dependencyGraph) Less(i, j int) bool { return a[i].name < a[j].name }
-func (a dependencyGraph) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+// BuildDependencyGraph orders stmts so that every statement comes after the statements it depends on.
+// Sources which aren't declared by any statement are checked with exists, when exists is nil they are undefined.
+// If the statements can't be ordered the statements which could be are returned with a *DependencyError.
+func BuildDependencyGraph(stmts []Stmt, exists SourceExists) ([]Stmt, error) {
+	derr := &DependencyError{Undefined: map[string][]string{}}
 
-func BuildDependencyGraph(stmts []Stmt) []Stmt {
-	stmtMap := map[string]graphitem{}
+	stmtMap := map[string]*graphitem{}
+	var names []string
 	for _, item := range stmts {
 		n := strings.ToUpper(item.GetName())
-		stmtMap[n] = graphitem{
-			name:       n,
-			stmt:       item,
-			depends:    map[string]graphitem{},
-			dependants: map[string]graphitem{},
+		if _, ok := stmtMap[n]; ok {
+			if !contains(derr.Duplicates, item.GetName()) {
+				derr.Duplicates = append(derr.Duplicates, item.GetName())
+			}
+			continue
 		}
+		stmtMap[n] = &graphitem{
+			name:    n,
+			stmt:    item,
+			depends: map[string]bool{},
+		}
+		names = append(names, n)
 	}
+	// make the result deterministic for testing
+	sort.Strings(names)
 
-	// build the graphitem
-	var stmtDeps []graphitem
-	for _, stmtDep := range stmtMap {
-		dataSources := stmtDep.stmt.GetDataSources()
-		for _, dataSource := range dataSources {
-			dependantStmtDep, ok := stmtMap[strings.ToUpper(dataSource)]
-			if ok {
-				stmtDep.depends[dependantStmtDep.name] = dependantStmtDep
-				dependantStmtDep.dependants[stmtDep.name] = stmtDep
+	// build the graph
+	known := map[string]bool{}
+	for _, n := range names {
+		item := stmtMap[n]
+		for _, dataSource := range Dependencies(item.stmt) {
+			source := strings.ToUpper(dataSource)
+			if dep, ok := stmtMap[source]; ok {
+				if !item.depends[source] {
+					item.depends[source] = true
+					dep.dependants = append(dep.dependants, n)
+				}
+				continue
+			}
+			found, checked := known[source]
+			if !checked && exists != nil {
+				var err error
+				if found, err = exists(dataSource); err != nil {
+					return nil, err
+				}
+				known[source] = found
+			}
+			if name := item.stmt.GetName(); !found && !contains(derr.Undefined[name], dataSource) {
+				derr.Undefined[name] = append(derr.Undefined[name], dataSource)
 			}
 		}
-		stmtDeps = append(stmtDeps, stmtDep)
 	}
 
-	// make the result deterministic for testing
-	sort.Sort(dependencyGraph(stmtDeps))
-
+	// repeatedly take the first statement which isn't waiting on anything
 	var order []Stmt
-	for i := 0; len(stmtDeps) > 0; i++ {
-		item := stmtDeps[i]
-		if len(item.depends) == 0 {
-			stmtDeps = append(stmtDeps[:i], stmtDeps[i+1:]...)
-			// this is a root (or now is a root)
-			order = append(order, item.stmt)
-			// remove us as a blocker from our dependants
-			for _, dep := range item.dependants {
-				delete(dep.depends, item.name)
+	remaining := names
+	for len(remaining) > 0 {
+		i := 0
+		for ; i < len(remaining) && len(stmtMap[remaining[i]].depends) > 0; i++ {
+		}
+		if i == len(remaining) {
+			// everything left is blocked
+			break
+		}
+		item := stmtMap[remaining[i]]
+		remaining = append(remaining[:i:i], remaining[i+1:]...)
+		order = append(order, item.stmt)
+		// remove us as a blocker from our dependants
+		for _, dep := range item.dependants {
+			delete(stmtMap[dep].depends, item.name)
+		}
+	}
+	derr.Cycles = findCycles(remaining, stmtMap)
+
+	if len(derr.Cycles) > 0 || len(derr.Duplicates) > 0 || len(derr.Undefined) > 0 {
+		return order, derr
+	}
+	return order, nil
+}
+
+// findCycles returns a cycle for each group of the blocked statements which depend on each other, statements which
+// are only blocked by a cycle aren't included
+func findCycles(blocked []string, stmtMap map[string]*graphitem) [][]string {
+	var cycles [][]string
+	inCycle := map[string]bool{}
+	for _, start := range blocked {
+		if inCycle[start] {
+			continue
+		}
+		path := []string{start}
+		visited := map[string]bool{start: true}
+		var visit func(n string) bool
+		visit = func(n string) bool {
+			var depends []string
+			for dep := range stmtMap[n].depends {
+				depends = append(depends, dep)
+			}
+			sort.Strings(depends)
+			for _, dep := range depends {
+				if dep == start {
+					return true
+				}
+				if visited[dep] || inCycle[dep] {
+					continue
+				}
+				visited[dep] = true
+				path = append(path, dep)
+				if visit(dep) {
+					return true
+				}
+				path = path[:len(path)-1]
+			}
+			return false
+		}
+		if visit(start) {
+			var cycle []string
+			for _, n := range path {
+				inCycle[n] = true
+				cycle = append(cycle, stmtMap[n].stmt.GetName())
+			}
+			cycles = append(cycles, cycle)
+		}
+	}
+	return cycles
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeKind is the kind of object a node in a Graph represents
+type NodeKind string
+
+const (
+	NodeKindStream = NodeKind(CreateObjectTypeStream)
+	NodeKindTable  = NodeKind(CreateObjectTypeTable)
+	NodeKindInsert = NodeKind("INSERT")
+	// NodeKindExternal is a source which isn't declared by any of the statements
+	NodeKindExternal = NodeKind("EXTERNAL")
+)
+
+// QueryType describes where the data of a node in a Graph comes from
+type QueryType string
+
+const (
+	// QueryTypeSource is declared over an existing topic
+	QueryTypeSource = QueryType("SOURCE")
+	// QueryTypePersistent is populated by a persistent query
+	QueryTypePersistent = QueryType("PERSISTENT")
+	// QueryTypeInsert is a persistent query inserting into another node
+	QueryTypeInsert = QueryType("INSERT")
+)
+
+// GraphNode is a statement or external source in a Graph
+type GraphNode struct {
+	Name      string    `json:"name"`
+	Label     string    `json:"label"`
+	Kind      NodeKind  `json:"kind"`
+	QueryType QueryType `json:"queryType,omitempty"`
+	Topic     string    `json:"topic,omitempty"`
+}
+
+// GraphEdge is data flowing from one node in a Graph to another
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph is the flow of data between statements
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// NewGraph builds the Graph of data flowing between stmts and any external sources they use, types are left out
+func NewGraph(stmts []Stmt) *Graph {
+	g := &Graph{}
+	declared := map[string]string{}
+	for _, stmt := range stmts {
+		node := GraphNode{
+			Name:  stmt.GetName(),
+			Label: stmt.GetName(),
+		}
+		var w *with
+		switch s := stmt.(type) {
+		case *createTypeStmt:
+			// no data flows through a type
+			continue
+		case *createStreamStmt:
+			node.Kind, node.QueryType, w = NodeKindStream, QueryTypePersistent, s.With
+			if s.Select == nil {
+				node.QueryType = QueryTypeSource
+			}
+		case *createTableStmt:
+			node.Kind, node.QueryType, w = NodeKindTable, QueryTypePersistent, s.With
+			if s.Select == nil {
+				node.QueryType = QueryTypeSource
 			}
-			i = -1
+		case *insertIntoStmt:
+			node.Kind, node.QueryType = NodeKindInsert, QueryTypeInsert
+			node.Label = fmt.Sprintf("%s %s", ReservedInsert, s.Name)
+		case *insertValuesStmt:
+			node.Kind = NodeKindInsert
+			node.Label = fmt.Sprintf("%s %s %s", ReservedInsert, s.Name, ReservedValues)
 		}
+		if w != nil {
+			node.Topic = strings.Trim(w.KafkaTopic, "'")
+		}
+		declared[strings.ToUpper(node.Name)] = node.Name
+		g.Nodes = append(g.Nodes, node)
+	}
+
+	var external []string
+	edges := map[GraphEdge]bool{}
+	for _, stmt := range stmts {
+		sources := stmt.GetDataSources()
+		var sinks []string
+		switch insert := stmt.(type) {
+		case *insertIntoStmt:
+			sinks = append(sinks, insert.Name)
+		case *insertValuesStmt:
+			sinks = append(sinks, insert.Name)
+		}
+		for i, name := range append(sources, sinks...) {
+			n, ok := declared[strings.ToUpper(name)]
+			if !ok {
+				n = name
+				declared[strings.ToUpper(name)] = name
+				external = append(external, name)
+			}
+			edge := GraphEdge{From: n, To: stmt.GetName()}
+			if i >= len(sources) {
+				edge = GraphEdge{From: stmt.GetName(), To: n}
+			}
+			if !edges[edge] {
+				edges[edge] = true
+				g.Edges = append(g.Edges, edge)
+			}
+		}
+	}
+	sort.Strings(external)
+	for _, name := range external {
+		g.Nodes = append(g.Nodes, GraphNode{Name: name, Label: name, Kind: NodeKindExternal})
 	}
-	return order
+	return g
 }
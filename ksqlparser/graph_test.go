@@ -1,8 +1,11 @@
 package ksqlparser
 
 import (
-	"github.com/go-test/deep"
+	"encoding/json"
+	"strings"
 	"testing"
+
+	"github.com/go-test/deep"
 )
 
 func Test_buildDependencyGraph(t *testing.T) {
@@ -14,12 +17,11 @@ func Test_buildDependencyGraph(t *testing.T) {
 
 	want := []string{
 		"ATTRIBUTIONS_CONFIG_TB",
-		"ATTRIBUTIONS_ST",
 		"EMAIL_ATTRIBUTIONS_ST",
-		"INVOICES_ST",
 		"PAGE_EVENT_2_ST",
 		"PROMOCODE_ATTRIBUTIONS_ST",
-		"L0orFlaTlO_YV908JLFGuHeIQ78fw7P4ESJRe3VusLM=",
+		"ATTRIBUTIONS_ST",
+		"INVOICES_ST",
 		"REPORTING_TB",
 		"sessions_engagements_displays_count",
 		"SESSION_ACTIONS_ST",
@@ -30,17 +32,156 @@ func Test_buildDependencyGraph(t *testing.T) {
 		"SESSION_ACTIONS_V2_TB",
 	}
 
-	g := BuildDependencyGraph(stmts)
+	g, err := BuildDependencyGraph(stmts, nil)
+	if err != nil {
+		t.Error(err)
+	}
 
-	var queryResult string
 	var got []string
 	for _, i := range g {
 		got = append(got, i.GetName())
-
-		queryResult = queryResult + "\n" + i.String()
 	}
 
 	if diff := deep.Equal(got, want); diff != nil {
 		t.Errorf("BuildDependencyGraph() want = %v, got %v", want, got)
 	}
 }
+
+func Test_buildDependencyGraphErrors(t *testing.T) {
+	stmts, err := Parse(`
+CREATE STREAM a AS SELECT * FROM c EMIT CHANGES;
+CREATE STREAM b AS SELECT * FROM a EMIT CHANGES;
+CREATE STREAM c AS SELECT * FROM b EMIT CHANGES;
+CREATE STREAM d AS SELECT * FROM c EMIT CHANGES;
+CREATE STREAM e AS SELECT * FROM e EMIT CHANGES;
+CREATE STREAM f AS SELECT * FROM missing LEFT JOIN server ON missing.a = server.a EMIT CHANGES;
+CREATE STREAM f (a STRING) WITH (KAFKA_TOPIC='f');
+INSERT INTO undeclared SELECT * FROM f;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exists := func(name string) (bool, error) {
+		return name == "server", nil
+	}
+	order, err := BuildDependencyGraph(stmts, exists)
+	derr, ok := err.(*DependencyError)
+	if !ok {
+		t.Fatalf("BuildDependencyGraph() error = %v, want a *DependencyError", err)
+	}
+
+	var got []string
+	for _, stmt := range order {
+		got = append(got, stmt.GetName())
+	}
+	if diff := deep.Equal(got, []string{"f", stmts[7].GetName()}); diff != nil {
+		t.Errorf("BuildDependencyGraph() order %v", diff)
+	}
+	if diff := deep.Equal(derr.Cycles, [][]string{{"a", "c", "b"}, {"e"}}); diff != nil {
+		t.Errorf("BuildDependencyGraph() cycles %v", diff)
+	}
+	if diff := deep.Equal(derr.Duplicates, []string{"f"}); diff != nil {
+		t.Errorf("BuildDependencyGraph() duplicates %v", diff)
+	}
+	want := map[string][]string{
+		"f":                {"missing"},
+		stmts[7].GetName(): {"undeclared"},
+	}
+	if diff := deep.Equal(derr.Undefined, want); diff != nil {
+		t.Errorf("BuildDependencyGraph() undefined %v", diff)
+	}
+	if derr.Error() == "" {
+		t.Error("BuildDependencyGraph() error has no message")
+	}
+}
+
+func Test_buildDependencyGraphTypes(t *testing.T) {
+	stmts, err := Parse(`
+CREATE STREAM people (name STRING, home address) WITH (KAFKA_TOPIC='people');
+CREATE TYPE address AS STRUCT<street STRING, postcode postcode>;
+CREATE TYPE postcode AS STRING;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := BuildDependencyGraph(stmts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, stmt := range order {
+		got = append(got, stmt.GetName())
+	}
+	if diff := deep.Equal(got, []string{"postcode", "address", "people"}); diff != nil {
+		t.Errorf("BuildDependencyGraph() order %v", diff)
+	}
+}
+
+func TestNewGraph(t *testing.T) {
+	stmts, err := Parse(`
+CREATE STREAM pages (a STRING) WITH (KAFKA_TOPIC='pages', VALUE_FORMAT='JSON');
+CREATE TABLE counts AS SELECT a, COUNT(*) FROM pages GROUP BY a EMIT CHANGES;
+INSERT INTO pages SELECT a FROM clicks;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insert := stmts[2].GetName()
+
+	g := NewGraph(stmts)
+
+	wantNodes := []GraphNode{
+		{Name: "pages", Label: "pages", Kind: NodeKindStream, QueryType: QueryTypeSource, Topic: "pages"},
+		{Name: "counts", Label: "counts", Kind: NodeKindTable, QueryType: QueryTypePersistent},
+		{Name: insert, Label: "INSERT INTO pages", Kind: NodeKindInsert, QueryType: QueryTypeInsert},
+		{Name: "clicks", Label: "clicks", Kind: NodeKindExternal},
+	}
+	if diff := deep.Equal(g.Nodes, wantNodes); diff != nil {
+		t.Errorf("NewGraph() nodes %v", diff)
+	}
+	wantEdges := []GraphEdge{
+		{From: "pages", To: "counts"},
+		{From: "clicks", To: insert},
+		{From: insert, To: "pages"},
+	}
+	if diff := deep.Equal(g.Edges, wantEdges); diff != nil {
+		t.Errorf("NewGraph() edges %v", diff)
+	}
+
+	dot := g.DOT()
+	for _, want := range []string{
+		`"pages" [label="pages` + "\n" + `topic: pages", shape=box, style="rounded,filled", fillcolor="#dae8fc"];`,
+		`"clicks" [label="clicks", shape=box, style=dashed];`,
+		`"pages" -> "counts";`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT() got = %s, want it to contain %s", dot, want)
+		}
+	}
+
+	mermaid := g.Mermaid()
+	for _, want := range []string{
+		`n0("pages<br/>topic: pages")`,
+		`n1[("counts")]`,
+		"class n2 insert",
+		`n3{{"clicks"}}`,
+		"n0 --> n1",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid() got = %s, want it to contain %s", mermaid, want)
+		}
+	}
+
+	b, err := g.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Graph
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(&decoded, g); diff != nil {
+		t.Errorf("JSON() round trip %v", diff)
+	}
+}
@@ -0,0 +1,88 @@
+package ksqlparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// nodeFillColours are the fill colours of nodes by QueryType
+var nodeFillColours = map[QueryType]string{
+	QueryTypeSource:     "#dae8fc",
+	QueryTypePersistent: "#d5e8d4",
+	QueryTypeInsert:     "#fff2cc",
+}
+
+// dotShapes are the Graphviz shapes of nodes by NodeKind
+var dotShapes = map[NodeKind]string{
+	NodeKindStream:   `shape=box, style="rounded,filled"`,
+	NodeKindTable:    `shape=cylinder, style=filled`,
+	NodeKindInsert:   `shape=cds, style=filled`,
+	NodeKindExternal: `shape=box, style=dashed`,
+}
+
+// mermaidShapes are the opening and closing brackets of nodes by NodeKind
+var mermaidShapes = map[NodeKind][2]string{
+	NodeKindStream:   {"(", ")"},
+	NodeKindTable:    {"[(", ")]"},
+	NodeKindInsert:   {">", "]"},
+	NodeKindExternal: {"{{", "}}"},
+}
+
+// label is the text of the node including its topic
+func (n GraphNode) label(newline string) string {
+	if n.Topic == "" {
+		return n.Label
+	}
+	return fmt.Sprintf("%s%stopic: %s", n.Label, newline, n.Topic)
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (g *Graph) DOT() string {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	var sb strings.Builder
+	sb.WriteString("digraph ksql {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		sb.WriteString(fmt.Sprintf(`  "%s" [label="%s", %s`,
+			quote.Replace(n.Name), quote.Replace(n.label("\n")), dotShapes[n.Kind]))
+		if colour, ok := nodeFillColours[n.QueryType]; ok {
+			sb.WriteString(fmt.Sprintf(`, fillcolor="%s"`, colour))
+		}
+		sb.WriteString("];\n")
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  \"%s\" -> \"%s\";\n", quote.Replace(e.From), quote.Replace(e.To)))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	quote := strings.NewReplacer(`"`, "#quot;")
+	ids := map[string]string{}
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, queryType := range []QueryType{QueryTypeSource, QueryTypePersistent, QueryTypeInsert} {
+		sb.WriteString(fmt.Sprintf("  classDef %s fill:%s\n", strings.ToLower(string(queryType)), nodeFillColours[queryType]))
+	}
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.Name] = id
+		shape := mermaidShapes[n.Kind]
+		sb.WriteString(fmt.Sprintf("  %s%s\"%s\"%s\n", id, shape[0], quote.Replace(n.label("<br/>")), shape[1]))
+		if n.QueryType != "" {
+			sb.WriteString(fmt.Sprintf("  class %s %s\n", id, strings.ToLower(string(n.QueryType))))
+		}
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %s --> %s\n", ids[e.From], ids[e.To]))
+	}
+	return sb.String()
+}
+
+// JSON renders the graph as indented JSON
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
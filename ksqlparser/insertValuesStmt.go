@@ -0,0 +1,105 @@
+package ksqlparser
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+type insertValuesStmt struct {
+	stmt
+	Columns []string
+	Values  []Expression
+}
+
+func (s *insertValuesStmt) GetActionType() StmtActionType {
+	return StmtTypeInsertValues
+}
+
+// GetName is a hash of the normalised statement as a row is identified by its content, not how it's written
+func (s *insertValuesStmt) GetName() string {
+	hasher := sha256.New()
+	hasher.Write([]byte(Normalise(s.String())))
+	return base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+}
+
+func (s *insertValuesStmt) GetDataSources() []string {
+	return nil
+}
+
+func (s *insertValuesStmt) String() string {
+	sb := []string{string(s.stmt.Type), s.Name}
+	if len(s.Columns) > 0 {
+		sb = append(sb, ReservedOpenParens+strings.Join(s.Columns, ReservedComma+" ")+ReservedCloseParens)
+	}
+	var values []string
+	for _, v := range s.Values {
+		values = append(values, v.String())
+	}
+	sb = append(sb, ReservedValues, ReservedOpenParens+strings.Join(values, ReservedComma+" ")+ReservedCloseParens,
+		ReservedEndOfStatement)
+	return strings.Join(sb, " ")
+}
+
+// parseInsertValues parses the rest of an INSERT INTO name [(columns)] VALUES (values)
+func (p *parser) parseInsertValues(item, name string) (Stmt, error) {
+	result := &insertValuesStmt{
+		stmt: stmt{
+			Type: StmtActionType(item),
+			Name: name,
+		},
+	}
+	if next, l := p.peekWithLength(ReservedOpenParens); next == ReservedOpenParens {
+		p.popLength(l)
+		for {
+			c, l := p.peekQuotedIdentifierWithLength()
+			if l == 0 {
+				c, l = p.peekIdentifierWithLength()
+				if l == 0 || !isIdentifier(c) {
+					return nil, p.Error("[column]")
+				}
+			}
+			p.popLength(l)
+			result.Columns = append(result.Columns, c)
+			next, err := p.popOrError(ReservedComma, ReservedCloseParens)
+			if err != nil {
+				return nil, err
+			}
+			if next == ReservedCloseParens {
+				break
+			}
+		}
+	}
+
+	if _, err := p.popOrError(ReservedValues); err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedOpenParens); err != nil {
+		return nil, err
+	}
+	for {
+		if len(result.Columns) > 0 && len(result.Values) == len(result.Columns) {
+			return nil, p.Error(fmt.Sprintf("%d values", len(result.Columns)))
+		}
+		v, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		result.Values = append(result.Values, v)
+		next, err := p.popOrError(ReservedComma, ReservedCloseParens)
+		if err != nil {
+			return nil, err
+		}
+		if next == ReservedCloseParens {
+			break
+		}
+	}
+	if len(result.Columns) > 0 && len(result.Values) != len(result.Columns) {
+		return nil, p.Error(fmt.Sprintf("%d values", len(result.Columns)))
+	}
+	if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
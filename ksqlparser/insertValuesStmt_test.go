@@ -0,0 +1,96 @@
+package ksqlparser
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestParseInsertValues(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want Stmt
+	}{
+		{
+			name: "insert values with columns",
+			sql:  "INSERT INTO currencies (code, name, minor_units) VALUES ('GBP', 'Pound sterling', 2);",
+			want: &insertValuesStmt{
+				stmt:    stmt{Type: StmtActionType(ReservedInsert), Name: "currencies"},
+				Columns: []string{"code", "name", "minor_units"},
+				Values: []Expression{
+					&basicExpression{Name: "'GBP'"},
+					&basicExpression{Name: "'Pound sterling'"},
+					&basicExpression{Name: "2"},
+				},
+			},
+		},
+		{
+			name: "insert values without columns",
+			sql:  "insert into currencies values ('EUR', UCASE('euro'), 2);",
+			want: &insertValuesStmt{
+				stmt: stmt{Type: StmtActionType(ReservedInsert), Name: "currencies"},
+				Values: []Expression{
+					&basicExpression{Name: "'EUR'"},
+					&functionExpression{Name: "UCASE", Params: []Expression{&basicExpression{Name: "'euro'"}}},
+					&basicExpression{Name: "2"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(stmts, []Stmt{tt.want}); diff != nil {
+				t.Error(diff)
+			}
+			if got := stmts[0].GetActionType(); got != StmtTypeInsertValues {
+				t.Errorf("GetActionType() got = %s, want %s", got, StmtTypeInsertValues)
+			}
+			if got := Dependencies(stmts[0]); deep.Equal(got, []string{"currencies"}) != nil {
+				t.Errorf("Dependencies() got = %v, want [currencies]", got)
+			}
+			// the string form should parse to the same statement
+			reparsed, err := Parse(stmts[0].String())
+			if err != nil {
+				t.Fatalf("String() output does not parse: %v", err)
+			}
+			if reparsed[0].GetName() != stmts[0].GetName() {
+				t.Errorf("String() changed the statement got = %s, want %s", reparsed[0].String(), stmts[0].String())
+			}
+		})
+	}
+}
+
+func TestParseInsertValuesErrors(t *testing.T) {
+	for _, sql := range []string{
+		"INSERT INTO currencies (code, name) VALUES ('GBP');",
+		"INSERT INTO currencies (code) VALUES ('GBP', 'Pound sterling');",
+		"INSERT INTO currencies (code) ('GBP');",
+		"INSERT INTO currencies VALUES 'GBP';",
+	} {
+		if _, err := Parse(sql); err == nil {
+			t.Errorf("Parse(%s) expected an error", sql)
+		}
+	}
+}
+
+func TestInsertValuesName(t *testing.T) {
+	stmts, err := Parse(
+		"INSERT INTO currencies (code, name) VALUES ('GBP', 'Pound sterling');",
+		"insert into CURRENCIES (CODE,   NAME)\n\tvalues ('GBP', 'Pound sterling');",
+		"INSERT INTO currencies (code, name) VALUES ('gbp', 'Pound sterling');",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmts[0].GetName() != stmts[1].GetName() {
+		t.Errorf("GetName() differs when only the case and whitespace differ")
+	}
+	if stmts[0].GetName() == stmts[2].GetName() {
+		t.Errorf("GetName() is the same when a value differs")
+	}
+}
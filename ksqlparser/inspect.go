@@ -0,0 +1,213 @@
+package ksqlparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Properties are the WITH properties of a CREATE STREAM, CREATE TABLE or CREATE CONNECTOR statement keyed by
+// property name. Quoted values keep their quotes, the keys of connector properties are unquoted. It returns nil for
+// statements without a WITH.
+func Properties(stmt Stmt) map[string]string {
+	var w *with
+	switch s := stmt.(type) {
+	case *createStreamStmt:
+		w = s.With
+	case *createTableStmt:
+		w = s.With
+	case *createConnectorStmt:
+		result := map[string]string{}
+		for _, prop := range s.Properties {
+			result[strings.Trim(prop.Key, "`\"'")] = prop.Value
+		}
+		return result
+	}
+	if w == nil {
+		return nil
+	}
+	result := map[string]string{}
+	set := func(prop, value string) {
+		if value != "" {
+			result[prop] = value
+		}
+	}
+	set(WithPropertyKafkaTopic, w.KafkaTopic)
+	set(WithPropertyValueFormat, string(w.ValueFormat))
+	set(WithPropertyKey, w.Key)
+	set(WithPropertyTimeStamp, w.TimeStamp)
+	if w.Partitions > 0 {
+		set(WithPropertyPartitions, strconv.Itoa(w.Partitions))
+	}
+	if w.Replicas > 0 {
+		set(WithPropertyReplicas, strconv.Itoa(w.Replicas))
+	}
+	return result
+}
+
+// ConnectorTypeOf is whether the connector created by stmt is a source or a sink, it's false for any other statement
+func ConnectorTypeOf(stmt Stmt) (ConnectorType, bool) {
+	if s, ok := stmt.(*createConnectorStmt); ok {
+		return s.ConnectorType, true
+	}
+	return "", false
+}
+
+// DroppedConnector is the name of the connector dropped by stmt, it's false for any other statement
+func DroppedConnector(stmt Stmt) (string, bool) {
+	if s, ok := stmt.(*dropConnectorStmt); ok {
+		return s.Name, true
+	}
+	return "", false
+}
+
+// expressions are the top level expressions of stmt
+func expressions(stmt Stmt) []Expression {
+	var expressions []Expression
+	conditions := func(c *[]*Condition) {
+		if c == nil {
+			return
+		}
+		for _, cond := range *c {
+			expressions = append(expressions, cond.Operand1, cond.Operand2)
+		}
+	}
+	streamSelect := func(sel *streamSelect) {
+		if sel == nil {
+			return
+		}
+		for _, e := range sel.Expressions {
+			expressions = append(expressions, e.Expression)
+		}
+		if sel.Joins != nil {
+			for _, j := range *sel.Joins {
+				conditions(&j.Conditions)
+			}
+		}
+		conditions(sel.Where)
+	}
+
+	switch s := stmt.(type) {
+	case *createStreamStmt:
+		streamSelect(s.Select)
+	case *insertIntoStmt:
+		streamSelect(s.Select)
+	case *insertValuesStmt:
+		expressions = append(expressions, s.Values...)
+	case *createTableStmt:
+		if s.Select != nil {
+			for _, e := range s.Select.Expressions {
+				expressions = append(expressions, e.Expression)
+			}
+			if s.Select.Joins != nil {
+				for _, j := range *s.Select.Joins {
+					conditions(&j.Conditions)
+				}
+			}
+			conditions(s.Select.Where)
+			for _, e := range s.Select.Group {
+				expressions = append(expressions, e.Expression)
+			}
+			conditions(s.Select.Having)
+		}
+	}
+	return expressions
+}
+
+// walkExpressions calls visit with every expression in stmt, nested expressions are visited after their parent
+func walkExpressions(stmt Stmt, visit func(e Expression)) {
+	var walk func(e Expression)
+	walk = func(e Expression) {
+		visit(e)
+		switch e := e.(type) {
+		case *functionExpression:
+			for _, p := range e.Params {
+				walk(p)
+			}
+		case *castExpression:
+			walk(e.InnerExpression)
+		case *caseWhenExpression:
+			for _, w := range e.When {
+				walk(w.Operand1)
+				walk(w.Operand2)
+			}
+			walk(e.Then)
+			if e.Else != nil {
+				walk(e.Else)
+			}
+		case *indexExpression:
+			walk(e.Expression)
+			walk(e.Index)
+		case *operatorExpression:
+			walk(e.LeftExpression)
+			walk(e.RightExpression)
+		}
+	}
+	for _, e := range expressions(stmt) {
+		walk(e)
+	}
+}
+
+// Functions are the upper case names of the functions called anywhere in stmt in the order they are first found
+func Functions(stmt Stmt) []string {
+	var result []string
+	walkExpressions(stmt, func(e Expression) {
+		if f, ok := e.(*functionExpression); ok {
+			if name := strings.ToUpper(f.Name); !contains(result, name) {
+				result = append(result, name)
+			}
+		}
+	})
+	return result
+}
+
+// Types are the names of the custom types used by stmt, in column definitions, casts or the definition of another
+// type, in the order they are first found
+func Types(stmt Stmt) []string {
+	var result []string
+	walkCustomTypes(stmt, func(d *customDataType) {
+		if !contains(result, d.Name) {
+			result = append(result, d.Name)
+		}
+	})
+	return result
+}
+
+// walkCustomTypes calls visit with each use of a custom type in stmt
+func walkCustomTypes(stmt Stmt, visit func(d *customDataType)) {
+	var walk func(d dataTypeDefinition)
+	walk = func(d dataTypeDefinition) {
+		switch d := d.(type) {
+		case *customDataType:
+			visit(d)
+		case *arrayTypeDataType:
+			walk(d.ItemType)
+		case *mapTypeDataType:
+			walk(d.KeyType)
+			walk(d.ValueType)
+		case *structTypeDataType:
+			for _, f := range d.Fields {
+				walk(f.Type)
+			}
+		}
+	}
+
+	var columns *columnDefinitions
+	switch s := stmt.(type) {
+	case *createStreamStmt:
+		columns = s.Columns
+	case *createTableStmt:
+		columns = s.Columns
+	case *createTypeStmt:
+		walk(s.Definition)
+	}
+	if columns != nil {
+		for _, c := range *columns {
+			walk(c.DataType)
+		}
+	}
+	walkExpressions(stmt, func(e Expression) {
+		if c, ok := e.(*castExpression); ok {
+			walk(c.DataType)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package ksqlparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProperties(t *testing.T) {
+	stmts, err := Parse(`
+CREATE STREAM orders (id STRING, amount DOUBLE) WITH (KAFKA_TOPIC='orders', VALUE_FORMAT='JSON', PARTITIONS=6, REPLICAS=3);
+CREATE STREAM big AS SELECT id, amount FROM orders WHERE amount > 100 EMIT CHANGES;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		WithPropertyKafkaTopic:  "'orders'",
+		WithPropertyValueFormat: "'JSON'",
+		WithPropertyPartitions:  "6",
+		WithPropertyReplicas:    "3",
+	}
+	if got := Properties(stmts[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("Properties() got = %v, want %v", got, want)
+	}
+	if got := Properties(stmts[1]); got != nil {
+		t.Errorf("Properties() got = %v, want nil", got)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	stmts, err := Parse(`
+CREATE TABLE totals AS SELECT id, SUM(CAST(amount AS INTEGER)) AS total, ucase(MAX(name)) AS name FROM orders WHERE ABS(amount) > 1 GROUP BY id HAVING COUNT(*) > 1 EMIT CHANGES;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"SUM", "UCASE", "MAX", "ABS", "COUNT"}
+	if got := Functions(stmts[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("Functions() got = %v, want %v", got, want)
+	}
+}
+
+func TestTypes(t *testing.T) {
+	stmts, err := Parse(`
+CREATE TYPE address AS STRUCT<street STRING, postcode postcode>;
+CREATE STREAM people (name STRING, home address, previous ARRAY<address>, tags MAP<STRING, tag>) WITH (KAFKA_TOPIC='people');
+CREATE STREAM labelled AS SELECT name, CAST(name AS label) AS label FROM people EMIT CHANGES;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"postcode"},
+		{"address", "tag"},
+		{"label"},
+	}
+	for i, stmt := range stmts {
+		if got := Types(stmt); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Types(%s) got = %v, want %v", stmt.GetName(), got, want[i])
+		}
+	}
+}
+
+func TestSetDefaultProperties(t *testing.T) {
+	stmts, err := Parse(`
+CREATE STREAM orders (id STRING) WITH (KAFKA_TOPIC='orders');
+CREATE STREAM big AS SELECT id FROM orders EMIT CHANGES;
+CREATE STREAM small WITH (PARTITIONS=1) AS SELECT id FROM orders EMIT CHANGES;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDefaultProperties(stmts, map[string]string{"PARTITIONS": "6", "REPLICAS": "3", "VALUE_FORMAT": "json"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{WithPropertyKafkaTopic: "'orders'", WithPropertyValueFormat: "'JSON'"},
+		{WithPropertyValueFormat: "'JSON'", WithPropertyPartitions: "6", WithPropertyReplicas: "3"},
+		{WithPropertyValueFormat: "'JSON'", WithPropertyPartitions: "1", WithPropertyReplicas: "3"},
+	}
+	for i, stmt := range stmts {
+		if got := Properties(stmt); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("SetDefaultProperties() %s got = %v, want %v", stmt.GetName(), got, want[i])
+		}
+	}
+	if _, err := Parse(Format(stmts, DefaultFormatOptions)); err != nil {
+		t.Errorf("SetDefaultProperties() doesn't parse once formatted: %v", err)
+	}
+
+	if err := SetDefaultProperties(stmts, map[string]string{"KAFKA_TOPIC": "x"}); err == nil {
+		t.Error("SetDefaultProperties() expected an error defaulting KAFKA_TOPIC")
+	}
+}
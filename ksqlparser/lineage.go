@@ -0,0 +1,296 @@
+package ksqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// aggregateFunctions combine the values of many rows
+var aggregateFunctions = []string{
+	FunctionTypeCount,
+	FunctionTypeCountDistinct,
+	FunctionTypeMax,
+	FunctionTypeMin,
+	FunctionTypeSum,
+	FunctionTypeTopK,
+	FunctionTypeTopKDistinct,
+	FunctionLatestByOffset,
+	FunctionEarliestByOffset,
+	FunctionCollectList,
+}
+
+// ColumnRef is a column of a data source, nested fields of structs are separated by "."
+type ColumnRef struct {
+	Source string `json:"source"`
+	Column string `json:"column"`
+}
+
+// ColumnLineage is where the value of an output column comes from
+type ColumnLineage struct {
+	// Column is the name of the output column
+	Column string `json:"column"`
+	// Inputs are the columns the value is derived from
+	Inputs []ColumnRef `json:"inputs,omitempty"`
+	// Functions are the functions applied to the inputs in the order they are found in the expression
+	Functions []string `json:"functions,omitempty"`
+	// Aggregated is true when the value combines many rows
+	Aggregated bool `json:"aggregated,omitempty"`
+	// Expression is the select expression of the column
+	Expression string `json:"expression"`
+}
+
+// StmtLineage is the column lineage of a statement with a select
+type StmtLineage struct {
+	// Name is the name of the statement
+	Name string `json:"name"`
+	// Output is the stream or table written to
+	Output string `json:"output"`
+	// Inputs are the sources read from
+	Inputs  []string        `json:"inputs"`
+	Columns []ColumnLineage `json:"columns"`
+}
+
+// Lineage returns the column lineage of every statement with a select.
+// Columns of sources declared by stmts are used to expand * and to work out which source of a join an unqualified
+// column belongs to.
+func Lineage(stmts []Stmt) []StmtLineage {
+	// columns of sources must be known before the statements which select from them
+	ordered, err := BuildDependencyGraph(stmts, func(string) (bool, error) { return true, nil })
+	if err != nil {
+		ordered = stmts
+	}
+
+	schemas := map[string][]string{}
+	lineage := map[Stmt]StmtLineage{}
+	for _, stmt := range ordered {
+		var columns *columnDefinitions
+		var sel *streamSelect
+		var output string
+		switch s := stmt.(type) {
+		case *createStreamStmt:
+			columns, sel, output = s.Columns, s.Select, s.Name
+		case *createTableStmt:
+			columns, output = s.Columns, s.Name
+			if s.Select != nil {
+				sel = &streamSelect{Expressions: s.Select.Expressions, Identifier: s.Select.Identifier, Joins: s.Select.Joins}
+			}
+		case *insertIntoStmt:
+			sel, output = s.Select, s.Name
+		}
+		output = canonicalName(output)
+
+		if sel == nil {
+			if columns != nil {
+				for _, c := range *columns {
+					schemas[output] = append(schemas[output], canonicalName(c.Name))
+				}
+			}
+			continue
+		}
+
+		l := selectLineage(sel, schemas)
+		l.Name, l.Output = stmt.GetName(), output
+		lineage[stmt] = l
+		if _, ok := schemas[output]; !ok {
+			for _, c := range l.Columns {
+				schemas[output] = append(schemas[output], c.Column)
+			}
+		}
+	}
+
+	// keep the order the statements were given in
+	var result []StmtLineage
+	for _, stmt := range stmts {
+		if l, ok := lineage[stmt]; ok {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// lineageScope resolves column references within a select
+type lineageScope struct {
+	// sources are the canonical names of the sources in the order they are joined
+	sources []string
+	// aliases maps aliases and names of sources to their canonical names
+	aliases map[string]string
+	schemas map[string][]string
+}
+
+func selectLineage(sel *streamSelect, schemas map[string][]string) StmtLineage {
+	scope := &lineageScope{aliases: map[string]string{}, schemas: schemas}
+	scope.addSource(sel.Identifier)
+	if sel.Joins != nil {
+		for _, j := range *sel.Joins {
+			scope.addSource(j.Identifier)
+		}
+	}
+
+	result := StmtLineage{Inputs: scope.sources}
+	generated := 0
+	for _, e := range sel.Expressions {
+		if b, ok := e.Expression.(*basicExpression); ok && isStar(b.Name) {
+			result.Columns = append(result.Columns, scope.star(b.Name)...)
+			continue
+		}
+
+		c := ColumnLineage{Expression: e.Expression.String()}
+		scope.walk(e.Expression, &c)
+		switch {
+		case e.Alias != "":
+			c.Column = canonicalName(e.Alias)
+		case len(c.Functions) == 0 && len(c.Inputs) == 1:
+			if b, ok := e.Expression.(*basicExpression); ok && !isLiteral(b.Name) {
+				path := strings.Split(c.Inputs[0].Column, ".")
+				c.Column = path[len(path)-1]
+				break
+			}
+			fallthrough
+		default:
+			c.Column = fmt.Sprintf("KSQL_COL_%d", generated)
+			generated++
+		}
+		result.Columns = append(result.Columns, c)
+	}
+	return result
+}
+
+func (s *lineageScope) addSource(i identifier) {
+	name := canonicalName(i.Name)
+	s.sources = append(s.sources, name)
+	s.aliases[name] = name
+	if i.Alias != "" {
+		s.aliases[canonicalName(i.Alias)] = name
+	}
+}
+
+// star expands * or source.* into the columns of the sources
+func (s *lineageScope) star(name string) []ColumnLineage {
+	sources := s.sources
+	if name != "*" {
+		sources = []string{s.resolveSource(strings.TrimSuffix(name, ".*"))}
+	}
+	var result []ColumnLineage
+	for _, source := range sources {
+		columns, ok := s.schemas[source]
+		if !ok {
+			// the columns aren't known so all we can say is they come from the source
+			result = append(result, ColumnLineage{
+				Column:     "*",
+				Inputs:     []ColumnRef{{Source: source, Column: "*"}},
+				Expression: name,
+			})
+			continue
+		}
+		for _, c := range columns {
+			result = append(result, ColumnLineage{
+				Column:     c,
+				Inputs:     []ColumnRef{{Source: source, Column: c}},
+				Expression: name,
+			})
+		}
+	}
+	return result
+}
+
+func (s *lineageScope) resolveSource(name string) string {
+	if source, ok := s.aliases[canonicalName(name)]; ok {
+		return source
+	}
+	return canonicalName(name)
+}
+
+// column resolves a reference such as a, src.a or src.a->b
+func (s *lineageScope) column(ref string) ColumnRef {
+	var path []string
+	for _, p := range strings.Split(ref, "->") {
+		path = append(path, canonicalName(strings.TrimSpace(p)))
+	}
+
+	if i := strings.Index(path[0], "."); i > 0 {
+		if source, ok := s.aliases[path[0][:i]]; ok {
+			path[0] = path[0][i+1:]
+			return ColumnRef{Source: source, Column: strings.Join(path, ".")}
+		}
+	}
+
+	// find the source which declares the column falling back to the first source
+	source := s.sources[0]
+	for _, candidate := range s.sources {
+		if contains(s.schemas[candidate], path[0]) {
+			source = candidate
+			break
+		}
+	}
+	return ColumnRef{Source: source, Column: strings.Join(path, ".")}
+}
+
+// walk adds the inputs and functions of e to c
+func (s *lineageScope) walk(e Expression, c *ColumnLineage) {
+	switch e := e.(type) {
+	case *basicExpression:
+		if isLiteral(e.Name) || isStar(e.Name) {
+			return
+		}
+		ref := s.column(e.Name)
+		for _, i := range c.Inputs {
+			if i == ref {
+				return
+			}
+		}
+		c.Inputs = append(c.Inputs, ref)
+	case *functionExpression:
+		name := strings.ToUpper(e.Name)
+		c.Functions = append(c.Functions, name)
+		if arrayContains(aggregateFunctions, name) {
+			c.Aggregated = true
+		}
+		for _, p := range e.Params {
+			s.walk(p, c)
+		}
+	case *castExpression:
+		c.Functions = append(c.Functions, FunctionCast)
+		s.walk(e.InnerExpression, c)
+	case *caseWhenExpression:
+		c.Functions = append(c.Functions, ReservedCaseWhen)
+		for _, w := range e.When {
+			s.walk(w.Operand1, c)
+			s.walk(w.Operand2, c)
+		}
+		s.walk(e.Then, c)
+		if e.Else != nil {
+			s.walk(e.Else, c)
+		}
+	case *indexExpression:
+		s.walk(e.Expression, c)
+		s.walk(e.Index, c)
+	case *operatorExpression:
+		c.Functions = append(c.Functions, e.Operator)
+		s.walk(e.LeftExpression, c)
+		s.walk(e.RightExpression, c)
+	}
+}
+
+// canonicalName is the name ksql uses for an identifier, unquoted identifiers are uppercased
+func canonicalName(name string) string {
+	if len(name) > 1 && (name[0] == '`' || name[0] == '"') && name[len(name)-1] == name[0] {
+		return name[1 : len(name)-1]
+	}
+	return strings.ToUpper(name)
+}
+
+func isStar(name string) bool {
+	return name == "*" || strings.HasSuffix(name, ".*")
+}
+
+// isLiteral reports whether the basic expression is a value rather than a column
+func isLiteral(name string) bool {
+	if name == "" || name[0] == '\'' || ('0' <= name[0] && name[0] <= '9') || name[0] == '-' {
+		return true
+	}
+	switch strings.ToUpper(name) {
+	case "NULL", "TRUE", "FALSE":
+		return true
+	}
+	return false
+}
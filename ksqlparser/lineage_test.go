@@ -0,0 +1,108 @@
+package ksqlparser
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+const lineageKSQL = `
+CREATE STREAM pages (session_id STRING, header STRUCT<ip STRING, agent STRING>, url STRING)
+	WITH (KAFKA_TOPIC='pages', VALUE_FORMAT='JSON');
+CREATE TABLE users (session_id STRING PRIMARY KEY, email STRING) WITH (KAFKA_TOPIC='users', VALUE_FORMAT='JSON');
+CREATE STREAM enriched AS SELECT p.header->ip AS ip, email, UCASE(url) url_upper, *
+	FROM pages AS p LEFT JOIN users AS u ON p.session_id = u.session_id EMIT CHANGES;
+CREATE TABLE visits AS SELECT ip, COUNT(*) AS total FROM enriched GROUP BY ip EMIT CHANGES;
+`
+
+func TestLineage(t *testing.T) {
+	stmts, err := Parse(lineageKSQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := Lineage(stmts)
+	want := []StmtLineage{
+		{
+			Name:   "enriched",
+			Output: "ENRICHED",
+			Inputs: []string{"PAGES", "USERS"},
+			Columns: []ColumnLineage{
+				{Column: "IP", Inputs: []ColumnRef{{Source: "PAGES", Column: "HEADER.IP"}}, Expression: "p.header->ip"},
+				{Column: "EMAIL", Inputs: []ColumnRef{{Source: "USERS", Column: "EMAIL"}}, Expression: "email"},
+				{Column: "URL_UPPER", Inputs: []ColumnRef{{Source: "PAGES", Column: "URL"}}, Functions: []string{"UCASE"}, Expression: "UCASE(url)"},
+				{Column: "SESSION_ID", Inputs: []ColumnRef{{Source: "PAGES", Column: "SESSION_ID"}}, Expression: "*"},
+				{Column: "HEADER", Inputs: []ColumnRef{{Source: "PAGES", Column: "HEADER"}}, Expression: "*"},
+				{Column: "URL", Inputs: []ColumnRef{{Source: "PAGES", Column: "URL"}}, Expression: "*"},
+				{Column: "SESSION_ID", Inputs: []ColumnRef{{Source: "USERS", Column: "SESSION_ID"}}, Expression: "*"},
+				{Column: "EMAIL", Inputs: []ColumnRef{{Source: "USERS", Column: "EMAIL"}}, Expression: "*"},
+			},
+		},
+		{
+			Name:   "visits",
+			Output: "VISITS",
+			Inputs: []string{"ENRICHED"},
+			Columns: []ColumnLineage{
+				{Column: "IP", Inputs: []ColumnRef{{Source: "ENRICHED", Column: "IP"}}, Expression: "ip"},
+				{Column: "TOTAL", Functions: []string{"COUNT"}, Aggregated: true, Expression: "COUNT(*)"},
+			},
+		},
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Lineage() %v", diff)
+	}
+}
+
+func TestOpenLineage(t *testing.T) {
+	stmts, err := Parse(lineageKSQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultOpenLineageOptions
+	opts.EventTime = time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)
+	b, err := OpenLineage(stmts, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []struct {
+		EventTime string `json:"eventTime"`
+		Job       struct {
+			Name string `json:"name"`
+		} `json:"job"`
+		Outputs []struct {
+			Name   string `json:"name"`
+			Facets struct {
+				ColumnLineage struct {
+					Fields map[string]struct {
+						InputFields []struct {
+							Name            string `json:"name"`
+							Field           string `json:"field"`
+							Transformations []struct {
+								Subtype string `json:"subtype"`
+							} `json:"transformations"`
+						} `json:"inputFields"`
+					} `json:"fields"`
+				} `json:"columnLineage"`
+			} `json:"facets"`
+		} `json:"outputs"`
+	}
+	if err := json.Unmarshal(b, &events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].EventTime != "2020-08-01T12:00:00Z" || events[0].Job.Name != "enriched" {
+		t.Fatalf("OpenLineage() got = %s", b)
+	}
+	ip := events[0].Outputs[0].Facets.ColumnLineage.Fields["IP"]
+	if len(ip.InputFields) != 1 || ip.InputFields[0].Name != "PAGES" || ip.InputFields[0].Field != "HEADER.IP" ||
+		ip.InputFields[0].Transformations[0].Subtype != "IDENTITY" {
+		t.Errorf("OpenLineage() IP lineage got = %+v", ip)
+	}
+	total := events[1].Outputs[0].Facets.ColumnLineage.Fields["TOTAL"]
+	if len(total.InputFields) != 0 {
+		t.Errorf("OpenLineage() TOTAL lineage got = %+v", total)
+	}
+}
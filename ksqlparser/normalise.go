@@ -0,0 +1,201 @@
+package ksqlparser
+
+import (
+	"sort"
+	"strings"
+)
+
+// formatProperties are WITH properties whose quoted values ksqlDB treats case insensitively
+var formatProperties = []string{
+	WithPropertyValueFormat,
+	"KEY_FORMAT",
+	"FORMAT",
+}
+
+// twoCharOperators are operators which must not be split into single character tokens
+var twoCharOperators = []string{"->", "<=", ">=", "!=", "<>", "||"}
+
+// Normalise returns a canonical form of the given ksql suitable for hashing.
+// Keywords and unquoted identifiers are uppercased, quoted identifiers which are already in canonical form are
+// unquoted, comments are removed, whitespace is collapsed and the properties of WITH clauses are sorted by name.
+// It works on tokens rather than the parsed AST so it may be applied to statements returned by ksqlDB as well as to
+// the output of Stmt.String().
+func Normalise(sql string) string {
+	tokens := sortWithProperties(normaliseTokens(sql))
+	for len(tokens) > 0 && tokens[len(tokens)-1] == ReservedEndOfStatement {
+		tokens = tokens[:len(tokens)-1]
+	}
+	tokens = append(tokens, ReservedEndOfStatement)
+	return strings.Join(tokens, " ")
+}
+
+func normaliseTokens(sql string) []string {
+	var tokens []string
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case isWhitespaceRune(rune(c)):
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+				continue
+			}
+			i += end + 4
+		case c == '\'':
+			end := quotedEnd(sql, i)
+			tokens = append(tokens, sql[i:end])
+			i = end
+		case c == '`' || c == '"':
+			end := quotedEnd(sql, i)
+			tokens = append(tokens, normaliseQuotedIdentifier(sql[i+1:end-1]))
+			i = end
+		case isWordRune(c):
+			start := i
+			for i < len(sql) && isWordRune(sql[i]) {
+				i++
+			}
+			tokens = append(tokens, strings.ToUpper(sql[start:i]))
+		default:
+			l := 1
+			for _, op := range twoCharOperators {
+				if strings.HasPrefix(sql[i:], op) {
+					l = len(op)
+					break
+				}
+			}
+			tokens = append(tokens, sql[i:i+l])
+			i += l
+		}
+	}
+	return tokens
+}
+
+// quotedEnd returns the index just past the closing quote of the quoted item starting at start
+func quotedEnd(sql string, start int) int {
+	q := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] != q {
+			continue
+		}
+		// a doubled quote is an escaped quote
+		if i+1 < len(sql) && sql[i+1] == q {
+			i++
+			continue
+		}
+		if sql[i-1] == '\\' {
+			continue
+		}
+		return i + 1
+	}
+	return len(sql)
+}
+
+// normaliseQuotedIdentifier unquotes identifiers which would be unchanged by uppercasing
+func normaliseQuotedIdentifier(s string) string {
+	if s != "" && s == strings.ToUpper(s) && !('0' <= s[0] && s[0] <= '9') {
+		bare := true
+		for i := 0; i < len(s); i++ {
+			if !isWordRune(s[i]) {
+				bare = false
+				break
+			}
+		}
+		if bare {
+			return s
+		}
+	}
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+func isWordRune(c byte) bool {
+	return ('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z') ||
+		('0' <= c && c <= '9') ||
+		c == '_' || c == '@' || c == '$'
+}
+
+type withProperty struct {
+	name   string
+	tokens []string
+}
+
+// sortWithProperties sorts the properties of any WITH ( ... ) clause by name
+func sortWithProperties(tokens []string) []string {
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i] != ReservedWith || tokens[i+1] != ReservedOpenParens {
+			continue
+		}
+		end := closingParens(tokens, i+1)
+		if end < 0 {
+			break
+		}
+		var props []withProperty
+		prop := withProperty{}
+		depth := 0
+		for _, t := range tokens[i+2 : end] {
+			switch t {
+			case ReservedOpenParens:
+				depth++
+			case ReservedCloseParens:
+				depth--
+			case ReservedComma:
+				if depth == 0 {
+					props = append(props, prop)
+					prop = withProperty{}
+					continue
+				}
+			}
+			if prop.name == "" {
+				prop.name = t
+			}
+			prop.tokens = append(prop.tokens, t)
+		}
+		if len(prop.tokens) > 0 {
+			props = append(props, prop)
+		}
+		sort.SliceStable(props, func(a, b int) bool { return props[a].name < props[b].name })
+
+		var sorted []string
+		for j, p := range props {
+			if j > 0 {
+				sorted = append(sorted, ReservedComma)
+			}
+			if arrayContains(formatProperties, p.name) {
+				for k, t := range p.tokens {
+					if strings.HasPrefix(t, "'") {
+						p.tokens[k] = strings.ToUpper(t)
+					}
+				}
+			}
+			sorted = append(sorted, p.tokens...)
+		}
+		result := append([]string{}, tokens[:i+2]...)
+		result = append(result, sorted...)
+		tokens = append(result, tokens[end:]...)
+		i = i + 2 + len(sorted)
+	}
+	return tokens
+}
+
+// closingParens returns the index of the parenthesis closing the one at open or -1
+func closingParens(tokens []string, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch tokens[i] {
+		case ReservedOpenParens:
+			depth++
+		case ReservedCloseParens:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,78 @@
+package ksqlparser
+
+import (
+	"testing"
+)
+
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+	}{
+		{
+			name: "keyword and identifier case is normalised",
+			a:    "create stream foo as select bar from baz emit changes;",
+			b:    "CREATE STREAM FOO AS SELECT BAR FROM BAZ EMIT CHANGES;",
+		},
+		{
+			name: "whitespace and comments are ignored",
+			a:    "CREATE STREAM FOO AS\n  SELECT bar -- the bar\n  FROM baz /* the\nbaz */ EMIT CHANGES;",
+			b:    "CREATE STREAM FOO AS SELECT bar FROM baz EMIT CHANGES",
+		},
+		{
+			name: "quoted identifiers in canonical form are unquoted",
+			a:    "CREATE STREAM `FOO` (`NS` STRING, `HEADER` STRUCT<`IP` STRING>) WITH (KAFKA_TOPIC='foo');",
+			b:    "CREATE STREAM foo (ns string, header STRUCT<ip string>) WITH (KAFKA_TOPIC='foo');",
+		},
+		{
+			name: "with properties are sorted and formats uppercased",
+			a:    "CREATE STREAM foo (ns string) WITH (kafka_topic='PageEvent2', value_format='json', PARTITIONS=1, REPLICAS=1);",
+			b:    "CREATE STREAM FOO (NS STRING) WITH (PARTITIONS=1, REPLICAS=1, KAFKA_TOPIC='PageEvent2', VALUE_FORMAT='JSON');",
+		},
+		{
+			name: "stmt String output matches the source",
+			a:    "CREATE TABLE table AS SELECT column as alias, * FROM tbl EMIT CHANGES;",
+			b: func() string {
+				stmts, _ := Parse("CREATE TABLE table AS SELECT column as alias, * FROM tbl EMIT CHANGES;")
+				return stmts[0].String()
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if a, b := Normalise(tt.a), Normalise(tt.b); a != b {
+				t.Errorf("Normalise() got = %s, want %s", a, b)
+			}
+		})
+	}
+
+	distinct := []struct {
+		name string
+		a    string
+		b    string
+	}{
+		{
+			name: "string literals keep their case",
+			a:    "SELECT * FROM foo WHERE bar = 'a';",
+			b:    "SELECT * FROM foo WHERE bar = 'A';",
+		},
+		{
+			name: "quoted identifiers keep their case",
+			a:    "CREATE STREAM `foo` (bar STRING) WITH (KAFKA_TOPIC='foo');",
+			b:    "CREATE STREAM foo (bar STRING) WITH (KAFKA_TOPIC='foo');",
+		},
+		{
+			name: "topic names keep their case",
+			a:    "CREATE STREAM foo (bar STRING) WITH (KAFKA_TOPIC='foo');",
+			b:    "CREATE STREAM foo (bar STRING) WITH (KAFKA_TOPIC='FOO');",
+		},
+	}
+	for _, tt := range distinct {
+		t.Run(tt.name, func(t *testing.T) {
+			if a, b := Normalise(tt.a), Normalise(tt.b); a == b {
+				t.Errorf("Normalise() got = %s for both", a)
+			}
+		})
+	}
+}
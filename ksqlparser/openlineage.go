@@ -0,0 +1,134 @@
+package ksqlparser
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	openLineageJobEventSchemaURL      = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/JobEvent"
+	openLineageColumnLineageSchemaURL = "https://openlineage.io/spec/facets/1-2-0/ColumnLineageDatasetFacet.json#/$defs/ColumnLineageDatasetFacet"
+)
+
+// OpenLineageOptions configure the events written by OpenLineage
+type OpenLineageOptions struct {
+	// Namespace is the namespace of the jobs and datasets e.g. kafka://broker:9092
+	Namespace string
+	// Producer is the URI of the software producing the events
+	Producer string
+	// EventTime is the time of the events, the current time is used when zero
+	EventTime time.Time
+}
+
+// DefaultOpenLineageOptions are the options used by the ksqlgraph command
+var DefaultOpenLineageOptions = OpenLineageOptions{
+	Namespace: "ksql",
+	Producer:  "https://github.com/mgazza/ksql_operator",
+}
+
+type openLineageEvent struct {
+	EventTime string               `json:"eventTime"`
+	Producer  string               `json:"producer"`
+	SchemaURL string               `json:"schemaURL"`
+	Job       openLineageJob       `json:"job"`
+	Inputs    []openLineageDataset `json:"inputs"`
+	Outputs   []openLineageDataset `json:"outputs"`
+}
+
+type openLineageJob struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type openLineageDataset struct {
+	Namespace string                 `json:"namespace"`
+	Name      string                 `json:"name"`
+	Facets    map[string]interface{} `json:"facets,omitempty"`
+}
+
+type openLineageColumnLineage struct {
+	Producer  string                                   `json:"_producer"`
+	SchemaURL string                                   `json:"_schemaURL"`
+	Fields    map[string]openLineageColumnLineageField `json:"fields"`
+}
+
+type openLineageColumnLineageField struct {
+	InputFields []openLineageInputField `json:"inputFields"`
+}
+
+type openLineageInputField struct {
+	Namespace       string                      `json:"namespace"`
+	Name            string                      `json:"name"`
+	Field           string                      `json:"field"`
+	Transformations []openLineageTransformation `json:"transformations"`
+}
+
+type openLineageTransformation struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	Description string `json:"description"`
+}
+
+// OpenLineage returns a JSON array of OpenLineage job events, one for each statement with a select, carrying the
+// column lineage of its output in the columnLineage facet
+func OpenLineage(stmts []Stmt, opts OpenLineageOptions) ([]byte, error) {
+	eventTime := opts.EventTime
+	if eventTime.IsZero() {
+		eventTime = time.Now()
+	}
+
+	events := []openLineageEvent{}
+	for _, l := range Lineage(stmts) {
+		event := openLineageEvent{
+			EventTime: eventTime.UTC().Format(time.RFC3339),
+			Producer:  opts.Producer,
+			SchemaURL: openLineageJobEventSchemaURL,
+			Job:       openLineageJob{Namespace: opts.Namespace, Name: l.Name},
+		}
+		for _, input := range l.Inputs {
+			event.Inputs = append(event.Inputs, openLineageDataset{Namespace: opts.Namespace, Name: input})
+		}
+
+		fields := map[string]openLineageColumnLineageField{}
+		for _, c := range l.Columns {
+			if c.Column == "*" {
+				// the columns of the source aren't known
+				continue
+			}
+			transformation := openLineageTransformation{
+				Type:        "DIRECT",
+				Subtype:     "TRANSFORMATION",
+				Description: c.Expression,
+			}
+			switch {
+			case c.Aggregated:
+				transformation.Subtype = "AGGREGATION"
+			case len(c.Functions) == 0:
+				transformation.Subtype = "IDENTITY"
+			}
+			field := openLineageColumnLineageField{InputFields: []openLineageInputField{}}
+			for _, input := range c.Inputs {
+				field.InputFields = append(field.InputFields, openLineageInputField{
+					Namespace:       opts.Namespace,
+					Name:            input.Source,
+					Field:           input.Column,
+					Transformations: []openLineageTransformation{transformation},
+				})
+			}
+			fields[c.Column] = field
+		}
+		event.Outputs = []openLineageDataset{{
+			Namespace: opts.Namespace,
+			Name:      l.Output,
+			Facets: map[string]interface{}{
+				"columnLineage": openLineageColumnLineage{
+					Producer:  opts.Producer,
+					SchemaURL: openLineageColumnLineageSchemaURL,
+					Fields:    fields,
+				},
+			},
+		}}
+		events = append(events, event)
+	}
+	return json.MarshalIndent(events, "", "  ")
+}
@@ -26,6 +26,8 @@ type Condition struct {
 	Operand2 Expression
 	// Conjunction is a following AND or OR
 	Conjunction string
+	// Comments are the comments around the condition
+	Comments Comments
 }
 
 func (c Condition) String() string {
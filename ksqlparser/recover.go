@@ -0,0 +1,75 @@
+package ksqlparser
+
+import (
+	"strings"
+)
+
+// statementKeywords start a top level statement, the parser resyncs at these after an error
+var statementKeywords = []string{
+	ReservedCreate,
+	"INSERT",
+	ReservedDrop,
+	ReservedDescribe,
+	ReservedSet,
+	ReservedUnset,
+	ReservedDefine,
+	ReservedUndefine,
+}
+
+// skipQuotedOrComment returns the end of the string, quoted identifier or comment starting at i in sql
+// or i if there isn't one
+func skipQuotedOrComment(sql string, i int) int {
+	switch {
+	case sql[i] == '\'' || sql[i] == '"' || sql[i] == '`':
+		return quotedEnd(sql, i)
+	case strings.HasPrefix(sql[i:], "--"):
+		if end := strings.Index(sql[i:], "\n"); end >= 0 {
+			return i + end + 1
+		}
+		return len(sql)
+	case strings.HasPrefix(sql[i:], "/*"):
+		if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(sql)
+	}
+	return i
+}
+
+// statementEnds returns the offset of each ; in sql which isn't part of a string, quoted identifier or comment
+func statementEnds(sql string) []int {
+	var ends []int
+	for i := 0; i < len(sql); {
+		if next := skipQuotedOrComment(sql, i); next != i {
+			i = next
+			continue
+		}
+		if strings.HasPrefix(sql[i:], ReservedEndOfStatement) {
+			ends = append(ends, i)
+		}
+		i++
+	}
+	return ends
+}
+
+// resyncOffset returns the offset of the first statement keyword in sql at or after from which isn't part of a
+// string, quoted identifier or comment. It returns -1 if there isn't one.
+func resyncOffset(sql string, from int) int {
+	for i := 0; i < len(sql); {
+		if next := skipQuotedOrComment(sql, i); next != i {
+			i = next
+			continue
+		}
+		if i >= from && (i == 0 || !isWordRune(sql[i-1])) {
+			for _, keyword := range statementKeywords {
+				end := i + len(keyword)
+				if end <= len(sql) && strings.EqualFold(sql[i:end], keyword) &&
+					(end == len(sql) || !isWordRune(sql[end])) {
+					return i
+				}
+			}
+		}
+		i++
+	}
+	return -1
+}
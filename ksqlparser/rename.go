@@ -0,0 +1,92 @@
+package ksqlparser
+
+import "strings"
+
+// Rename rewrites the names of the streams, tables, types and connectors declared and read by stmts, including those
+// in FROM and JOIN and the types of columns and casts, with rename. Quoted names are renamed inside their quotes.
+// Sources read without an alias are aliased with their original name so that columns qualified with it still resolve.
+func Rename(stmts []Stmt, rename func(name string) string) {
+	for _, s := range stmts {
+		switch s := s.(type) {
+		case *createStreamStmt:
+			s.Name = renameIdentifier(s.Name, rename)
+			if s.Select != nil {
+				renameSource(&s.Select.Identifier, rename)
+				renameJoins(s.Select.Joins, rename)
+			}
+		case *createTableStmt:
+			s.Name = renameIdentifier(s.Name, rename)
+			if s.Select != nil {
+				renameSource(&s.Select.Identifier, rename)
+				renameJoins(s.Select.Joins, rename)
+			}
+		case *createTypeStmt:
+			s.Name = renameIdentifier(s.Name, rename)
+		case *insertIntoStmt:
+			s.Name = renameIdentifier(s.Name, rename)
+			if s.Select != nil {
+				renameSource(&s.Select.Identifier, rename)
+				renameJoins(s.Select.Joins, rename)
+			}
+		case *insertValuesStmt:
+			s.Name = renameIdentifier(s.Name, rename)
+		case *createConnectorStmt:
+			s.Name = renameIdentifier(s.Name, rename)
+		case *dropConnectorStmt:
+			s.Name = renameIdentifier(s.Name, rename)
+		case *describeConnectorStmt:
+			s.Name = renameIdentifier(s.Name, rename)
+		}
+		walkCustomTypes(s, func(d *customDataType) {
+			d.Name = renameIdentifier(d.Name, rename)
+		})
+	}
+}
+
+// RenameTopics rewrites the KAFKA_TOPIC of the streams and tables created with a select by stmts with rename. Those
+// declared over a topic which already exists are left alone, as are those without KAFKA_TOPIC as their topic is
+// named after them. The topics of connectors are named by properties particular to each connector so they're left
+// alone too.
+func RenameTopics(stmts []Stmt, rename func(topic string) string) {
+	for _, s := range stmts {
+		var w *with
+		switch s := s.(type) {
+		case *createStreamStmt:
+			if s.Select != nil {
+				w = s.With
+			}
+		case *createTableStmt:
+			if s.Select != nil {
+				w = s.With
+			}
+		}
+		if w == nil || w.KafkaTopic == "" {
+			continue
+		}
+		w.KafkaTopic = "'" + rename(strings.Trim(w.KafkaTopic, "'")) + "'"
+	}
+}
+
+func renameJoins(joins *[]joinExpression, rename func(name string) string) {
+	if joins == nil {
+		return
+	}
+	for i := range *joins {
+		renameSource(&(*joins)[i].Identifier, rename)
+	}
+}
+
+func renameSource(i *identifier, rename func(name string) string) {
+	renamed := renameIdentifier(i.Name, rename)
+	if i.Alias == "" && renamed != i.Name {
+		i.Alias = i.Name
+	}
+	i.Name = renamed
+}
+
+func renameIdentifier(name string, rename func(name string) string) string {
+	if len(name) > 1 && (name[0] == '`' || name[0] == '"') && name[len(name)-1] == name[0] {
+		return string(name[0]) + rename(name[1:len(name)-1]) + string(name[0])
+	}
+	return rename(name)
+}
@@ -0,0 +1,113 @@
+package ksqlparser
+
+import (
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+	stmts, err := Parse(`
+CREATE STREAM orders (id STRING, customer STRING) WITH (KAFKA_TOPIC='orders', VALUE_FORMAT='JSON');
+CREATE TABLE customers (id STRING PRIMARY KEY, name STRING) WITH (KAFKA_TOPIC='customers', VALUE_FORMAT='JSON');
+CREATE STREAM enriched AS SELECT orders.id, c.name FROM orders LEFT JOIN customers AS c ON orders.customer = c.id EMIT CHANGES;
+INSERT INTO enriched SELECT orders.id, orders.customer FROM orders;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Rename(stmts, func(name string) string { return "tenant_" + name })
+
+	var names []string
+	for _, s := range stmts[:3] {
+		names = append(names, s.GetName())
+	}
+	want := []string{"tenant_orders", "tenant_customers", "tenant_enriched"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Rename() got name %s, want %s", names[i], want[i])
+		}
+	}
+
+	wantSQL := []string{
+		"CREATE STREAM tenant_enriched AS SELECT orders.id, c.name FROM tenant_orders AS orders LEFT JOIN tenant_customers AS c ON orders.customer = c.id EMIT CHANGES;",
+		"INSERT INTO tenant_enriched SELECT orders.id, orders.customer FROM tenant_orders AS orders;",
+	}
+	for i, want := range wantSQL {
+		if got := Normalise(stmts[i+2].String()); got != Normalise(want) {
+			t.Errorf("Rename() got = %s, want %s", got, Normalise(want))
+		}
+	}
+	if sources := stmts[2].GetDataSources(); sources[0] != "tenant_orders" || sources[1] != "tenant_customers" {
+		t.Errorf("Rename() got sources %v", sources)
+	}
+}
+
+func TestRenameTablesAndTypes(t *testing.T) {
+	stmts, err := Parse(`
+CREATE TYPE address AS STRUCT<street STRING, city STRING>;
+CREATE TABLE customers (id STRING PRIMARY KEY, home address) WITH (KAFKA_TOPIC='customers', VALUE_FORMAT='JSON');
+CREATE TABLE totals AS SELECT o.customer, COUNT(*) AS n FROM orders AS o LEFT JOIN customers ON o.customer = customers.id GROUP BY o.customer EMIT CHANGES;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Rename(stmts, func(name string) string { return "tenant_" + name })
+
+	if got := stmts[0].GetName(); got != "tenant_address" {
+		t.Errorf("Rename() got type %s, want tenant_address", got)
+	}
+	if got := Types(stmts[1]); len(got) != 1 || got[0] != "tenant_address" {
+		t.Errorf("Rename() got column types %v, want [tenant_address]", got)
+	}
+	want := "CREATE TABLE tenant_totals AS SELECT o.customer, COUNT(*) AS n FROM tenant_orders AS o LEFT JOIN tenant_customers AS customers ON o.customer = customers.id GROUP BY o.customer EMIT CHANGES;"
+	if got := Normalise(stmts[2].String()); got != Normalise(want) {
+		t.Errorf("Rename() got = %s, want %s", got, Normalise(want))
+	}
+	if sources := stmts[2].GetDataSources(); len(sources) != 2 || sources[1] != "tenant_customers" {
+		t.Errorf("Rename() got sources %v", sources)
+	}
+}
+
+func TestRenameTopics(t *testing.T) {
+	stmts, err := Parse(`
+CREATE STREAM orders (id STRING) WITH (KAFKA_TOPIC='orders', VALUE_FORMAT='JSON');
+CREATE STREAM big AS SELECT * FROM orders EMIT CHANGES;
+CREATE STREAM small WITH (KAFKA_TOPIC='small_orders') AS SELECT * FROM orders EMIT CHANGES;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	RenameTopics(stmts, func(topic string) string { return "tenant_" + topic })
+
+	want := []string{"'orders'", "", "'tenant_small_orders'"}
+	for i, stmt := range stmts {
+		if got := Properties(stmt)[WithPropertyKafkaTopic]; got != want[i] {
+			t.Errorf("RenameTopics() got topic %q for %s, want %q", got, stmt.GetName(), want[i])
+		}
+	}
+}
+
+func TestRenameConnectors(t *testing.T) {
+	stmts, err := Parse(`
+CREATE SOURCE CONNECTOR ` + "`orders-jdbc`" + ` WITH ('connector.class'='JdbcSourceConnector');
+DESCRIBE CONNECTOR legacy;
+DROP CONNECTOR legacy;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Rename(stmts, func(name string) string { return "tenant_" + name })
+
+	want := []string{"`tenant_orders-jdbc`", "DESCRIBE CONNECTOR tenant_legacy", "DROP CONNECTOR tenant_legacy"}
+	for i, stmt := range stmts {
+		if got := stmt.GetName(); got != want[i] {
+			t.Errorf("Rename() got name %s, want %s", got, want[i])
+		}
+	}
+	if got, _ := DroppedConnector(stmts[2]); got != "tenant_legacy" {
+		t.Errorf("Rename() got dropped connector %s, want tenant_legacy", got)
+	}
+}
@@ -0,0 +1,17 @@
+package ksqlparser
+
+// OrReplace returns a copy of a CREATE STREAM or CREATE TABLE stmt which is CREATE OR REPLACE, ok is false for any
+// other stmt
+func OrReplace(s Stmt) (replace Stmt, ok bool) {
+	switch s := s.(type) {
+	case *createStreamStmt:
+		cp := *s
+		cp.Type = StmtTypeCreateOrReplace
+		return &cp, true
+	case *createTableStmt:
+		cp := *s
+		cp.Type = StmtTypeCreateOrReplace
+		return &cp, true
+	}
+	return nil, false
+}
@@ -0,0 +1,55 @@
+package ksqlparser
+
+import (
+	"testing"
+)
+
+func TestOrReplace(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "stream",
+			sql:    "CREATE STREAM a AS SELECT * FROM b EMIT CHANGES;",
+			want:   "CREATE OR REPLACE STREAM a AS SELECT * FROM b EMIT CHANGES;",
+			wantOk: true,
+		},
+		{
+			name:   "table",
+			sql:    "CREATE TABLE a (id STRING PRIMARY KEY) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON');",
+			want:   "CREATE OR REPLACE TABLE a (id STRING PRIMARY KEY) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON');",
+			wantOk: true,
+		},
+		{
+			name: "insert",
+			sql:  "INSERT INTO a SELECT * FROM b;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := OrReplace(stmts[0])
+			if ok != tt.wantOk {
+				t.Fatalf("OrReplace() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if Normalise(got.String()) != Normalise(tt.want) {
+				t.Errorf("OrReplace() got = %s, want %s", Normalise(got.String()), Normalise(tt.want))
+			}
+			if got.GetActionType() != StmtTypeCreateOrReplace {
+				t.Errorf("OrReplace() got type %s", got.GetActionType())
+			}
+			if Normalise(stmts[0].String()) != Normalise(tt.sql) {
+				t.Errorf("OrReplace() changed the statement to %s", stmts[0].String())
+			}
+		})
+	}
+}
@@ -11,6 +11,18 @@ const (
 	ReservedCreateOrReplace = "CREATE OR REPLACE"
 	// ReservedReplace represents an REPLACE stmt
 	ReservedReplace = "REPLACE"
+	// ReservedDrop represents a DROP stmt
+	ReservedDrop = "DROP"
+	// ReservedDescribe represents a DESCRIBE stmt
+	ReservedDescribe = "DESCRIBE"
+	// ReservedSet represents a SET stmt
+	ReservedSet = "SET"
+	// ReservedUnset represents an UNSET stmt
+	ReservedUnset = "UNSET"
+	// ReservedDefine represents a DEFINE stmt
+	ReservedDefine = "DEFINE"
+	// ReservedUndefine represents an UNDEFINE stmt
+	ReservedUndefine = "UNDEFINE"
 
 	// ReservedEq -> "="
 	ReservedEq = "="
@@ -53,6 +65,20 @@ const (
 	ReservedTable = "TABLE"
 	// ReservedStream represents a STREAM keyword
 	ReservedStream = "STREAM"
+	// ReservedType represents a TYPE keyword
+	ReservedType = "TYPE"
+	// ReservedConnector represents a CONNECTOR keyword
+	ReservedConnector = "CONNECTOR"
+	// ReservedSourceConnector represents a SOURCE CONNECTOR keyword
+	ReservedSourceConnector = "SOURCE CONNECTOR"
+	// ReservedSinkConnector represents a SINK CONNECTOR keyword
+	ReservedSinkConnector = "SINK CONNECTOR"
+	// ReservedValues represents a VALUES keyword
+	ReservedValues = "VALUES"
+	// ReservedIfExists represents a IF EXISTS keyword
+	ReservedIfExists = "IF EXISTS"
+	// ReservedIfNotExists represents a IF NOT EXISTS keyword
+	ReservedIfNotExists = "IF NOT EXISTS"
 	// ReservedWith represents a WITH keyword
 	ReservedWith = "WITH"
 	// ReservedWhere represents a WHERE keyword
@@ -0,0 +1,139 @@
+package ksqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// setStmt sets a property for the statements which follow it, the property and value are quoted as written
+type setStmt struct {
+	stmt
+	Value string
+}
+
+func (s *setStmt) GetActionType() StmtActionType {
+	return s.Type
+}
+
+// GetName is prefixed so that it doesn't clash with the name of a stream or table
+func (s *setStmt) GetName() string {
+	return fmt.Sprintf("%s %s", ReservedSet, s.Name)
+}
+
+func (s *setStmt) GetDataSources() []string {
+	return nil
+}
+
+func (s *setStmt) String() string {
+	return fmt.Sprintf("%s %s%s%s%s", ReservedSet, s.Name, ReservedEq, s.Value, ReservedEndOfStatement)
+}
+
+// unsetStmt removes a property for the statements which follow it
+type unsetStmt struct {
+	stmt
+}
+
+func (s *unsetStmt) GetActionType() StmtActionType {
+	return s.Type
+}
+
+// GetName is prefixed so that it doesn't clash with the name of a stream or table
+func (s *unsetStmt) GetName() string {
+	return fmt.Sprintf("%s %s", ReservedUnset, s.Name)
+}
+
+func (s *unsetStmt) GetDataSources() []string {
+	return nil
+}
+
+func (s *unsetStmt) String() string {
+	return fmt.Sprintf("%s %s%s", ReservedUnset, s.Name, ReservedEndOfStatement)
+}
+
+// parseProperty pops a quoted property name or value
+func (p *parser) parseProperty(expected string) (string, error) {
+	s, l := p.peekQuotedStringWithLength()
+	if l == 0 {
+		return "", p.Error(expected)
+	}
+	p.popLength(l)
+	return s, nil
+}
+
+// parseSet parses the rest of a SET 'property'='value'
+func (p *parser) parseSet() (Stmt, error) {
+	n, err := p.parseProperty("[property]")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedEq); err != nil {
+		return nil, err
+	}
+	v, err := p.parseProperty("[value]")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+		return nil, err
+	}
+	return &setStmt{
+		stmt:  stmt{Type: StmtTypeSet, Name: n},
+		Value: v,
+	}, nil
+}
+
+// parseUnset parses the rest of an UNSET 'property'
+func (p *parser) parseUnset() (Stmt, error) {
+	n, err := p.parseProperty("[property]")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+		return nil, err
+	}
+	return &unsetStmt{
+		stmt: stmt{Type: StmtTypeUnset, Name: n},
+	}, nil
+}
+
+// StreamsProperties removes the SET, UNSET, DEFINE and UNDEFINE statements from stmts and returns the rest along with
+// the properties in effect for each, keyed by its name. Properties start as initial and each SET or UNSET changes them
+// for the statements which follow it, statements without any properties are left out.
+func StreamsProperties(stmts []Stmt, initial map[string]string) ([]Stmt, map[string]map[string]string) {
+	var result []Stmt
+	props := map[string]map[string]string{}
+	current := initial
+	for _, s := range stmts {
+		switch s := s.(type) {
+		case *setStmt:
+			current = copyProperties(current)
+			current[unquoteProperty(s.Name)] = unquoteProperty(s.Value)
+			continue
+		case *unsetStmt:
+			current = copyProperties(current)
+			delete(current, unquoteProperty(s.Name))
+			continue
+		case *defineStmt, *undefineStmt:
+			// variables have already been substituted
+			continue
+		}
+		result = append(result, s)
+		if len(current) > 0 {
+			props[s.GetName()] = current
+		}
+	}
+	return result, props
+}
+
+func copyProperties(props map[string]string) map[string]string {
+	result := make(map[string]string, len(props)+1)
+	for k, v := range props {
+		result[k] = v
+	}
+	return result
+}
+
+// unquoteProperty removes the quotes around s
+func unquoteProperty(s string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(s, "'"), "'")
+}
@@ -0,0 +1,101 @@
+package ksqlparser
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestParseSet(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want Stmt
+	}{
+		{
+			name: "set",
+			sql:  "SET 'auto.offset.reset'='earliest';",
+			want: &setStmt{
+				stmt:  stmt{Type: StmtTypeSet, Name: "'auto.offset.reset'"},
+				Value: "'earliest'",
+			},
+		},
+		{
+			name: "set lower case with spaces",
+			sql:  "set 'cache.max.bytes.buffering' = '0';",
+			want: &setStmt{
+				stmt:  stmt{Type: StmtTypeSet, Name: "'cache.max.bytes.buffering'"},
+				Value: "'0'",
+			},
+		},
+		{
+			name: "unset",
+			sql:  "UNSET 'auto.offset.reset';",
+			want: &unsetStmt{
+				stmt: stmt{Type: StmtTypeUnset, Name: "'auto.offset.reset'"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(stmts, []Stmt{tt.want}); diff != nil {
+				t.Error(diff)
+			}
+			reparsed, err := Parse(stmts[0].String())
+			if err != nil {
+				t.Fatalf("String() output does not parse: %v", err)
+			}
+			if diff := deep.Equal(reparsed, stmts); diff != nil {
+				t.Errorf("String() changed the statement %v", diff)
+			}
+		})
+	}
+}
+
+func TestParseSetErrors(t *testing.T) {
+	for _, sql := range []string{
+		"SET auto.offset.reset='earliest';",
+		"SET 'auto.offset.reset' earliest;",
+		"SET 'auto.offset.reset';",
+		"UNSET;",
+	} {
+		if _, err := Parse(sql); err == nil {
+			t.Errorf("Parse(%s) expected an error", sql)
+		}
+	}
+}
+
+func TestStreamsProperties(t *testing.T) {
+	stmts, err := Parse(`
+CREATE STREAM a (id INT) WITH (kafka_topic='a', value_format='JSON');
+SET 'auto.offset.reset'='earliest';
+CREATE STREAM b AS SELECT * FROM a;
+UNSET 'cache.max.bytes.buffering';
+INSERT INTO b SELECT * FROM a;
+UNSET 'auto.offset.reset';
+CREATE STREAM c AS SELECT * FROM a;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, props := StreamsProperties(stmts, map[string]string{"cache.max.bytes.buffering": "10000000"})
+	var names []string
+	for _, s := range got {
+		names = append(names, s.GetName())
+	}
+	if diff := deep.Equal(names, []string{"a", "b", stmts[4].GetName(), "c"}); diff != nil {
+		t.Errorf("statements %v", diff)
+	}
+	want := map[string]map[string]string{
+		"a":                {"cache.max.bytes.buffering": "10000000"},
+		"b":                {"cache.max.bytes.buffering": "10000000", "auto.offset.reset": "earliest"},
+		stmts[4].GetName(): {"auto.offset.reset": "earliest"},
+	}
+	if diff := deep.Equal(props, want); diff != nil {
+		t.Errorf("properties %v", diff)
+	}
+}
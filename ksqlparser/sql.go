@@ -45,48 +45,111 @@ var StringOptions = struct {
 	windowPrefix:      "\n",
 }
 
-// ParseMany takes a string slice representing many SQL queries and parses them into a stmt.stmt struct slice.
-// It may fail. If it fails, it will stop at the first failure.
+// Parse takes a string slice representing many SQL queries and parses them into a stmt.stmt struct slice.
+// When a statement fails to parse the parser resyncs at the next ; or statement keyword and carries on so that
+// every error is found. The statements which parsed are returned along with ParseErrors locating each failure in
+// its source.
 func Parse(sqls ...string) ([]Stmt, error) {
 	var qs []Stmt
+	var errs ParseErrors
+	n := 0
 	for _, s := range sqls {
-		innersqls := strings.Split(strings.TrimSpace(s), ReservedEndOfStatement)
-		for i, sql := range innersqls {
-			if sql == "" {
-				continue
-			}
-			q, err := parse(sql + ReservedEndOfStatement)
-			if err != nil {
-				return qs, fmt.Errorf("error parsing query %d: %v", i, err)
+		start := 0
+		for _, end := range append(statementEnds(s), len(s)) {
+			sql, offset := s[start:end], start
+			start = end + len(ReservedEndOfStatement)
+			for strings.TrimSpace(sql) != "" {
+				// leading whitespace is kept so we can tell if a comment follows the previous statement
+				p := &parser{sql: sql + ReservedEndOfStatement}
+				if end == len(s) {
+					// there was no ; in the source so make sure we don't add it to the end of a comment
+					p.sql = sql + "\n" + ReservedEndOfStatement
+				}
+				q, err := p.parse()
+				if len(qs) > 0 {
+					prev := qs[len(qs)-1].(commented).comments()
+					prev.Trailing = append(prev.Trailing, p.previousTrailing...)
+				} else if c, ok := q.(commented); ok && len(p.previousTrailing) > 0 {
+					c.comments().Leading = append(p.previousTrailing, c.comments().Leading...)
+				}
+				if err == nil {
+					if q != nil {
+						qs = append(qs, q)
+						n++
+					}
+					break
+				}
+				perr, ok := err.(*ParseError)
+				if !ok {
+					return qs, fmt.Errorf("error parsing statement %d: %v", n, err)
+				}
+				perr.locate(s, offset, n)
+				errs = append(errs, perr)
+				n++
+
+				// carry on from the next statement keyword, if there isn't one skip to the next ;
+				resync := resyncOffset(sql, max(perr.offset, 1))
+				if resync < 0 {
+					break
+				}
+				sql, offset = sql[resync:], offset+resync
 			}
-			qs = append(qs, q)
 		}
 	}
+	if len(errs) > 0 {
+		return qs, errs
+	}
 	return qs, nil
 }
 
-func parse(sql string) (Stmt, error) {
-	return (&parser{sql: strings.TrimSpace(sql)}).parse()
-}
-
 type parser struct {
 	i   int
 	sql string
 	//for error debugging
 	line int
 	col  int
+	// comments seen by popWhitespace which have not yet been attached to a node
+	comments []comment
+	// newline is true when popWhitespace has passed a newline since the last token
+	newline bool
+	// previousTrailing are the comments on the same line as the end of the previous statement
+	previousTrailing []string
 }
 
 func (p *parser) parse() (Stmt, error) {
+	p.popWhitespace()
+	p.previousTrailing = p.trailingComments()
+	if p.sql[p.i:] == ReservedEndOfStatement {
+		// there's nothing but comments
+		p.previousTrailing = append(p.previousTrailing, p.leadingComments()...)
+		return nil, nil
+	}
+	leading := p.leadingComments()
+
 	q, err := p.doParse()
 	if err == nil {
 		err = p.validate()
 	}
+	if c, ok := q.(commented); ok {
+		c.comments().Leading = leading
+		// keep any comments which couldn't be attached to a node with the statement
+		c.comments().Leading = append(c.comments().Leading, p.leadingComments()...)
+	}
 	return q, err
 }
 
+// stmtKeywords start each kind of statement the parser dispatches on, longer keywords come first so that they're
+// matched in full
+var stmtKeywords = []string{ReservedCreateOrReplace, ReservedCreate, ReservedReplace, ReservedInsert, ReservedDrop,
+	ReservedDescribe, ReservedSet, ReservedUnset, ReservedDefine, ReservedUndefine}
+
 func (p *parser) doParse() (Stmt, error) {
-	item := p.pop(ReservedCreateOrReplace, ReservedCreate, ReservedReplace, ReservedInsert)
+	// peek first so that an error points at the token which wasn't expected
+	item, l := p.peekWithLength(stmtKeywords...)
+	if !arrayContains(stmtKeywords, strings.ToUpper(item)) {
+		return nil, p.Error(strings.Join(stmtKeywords, " or "))
+	}
+	p.popLength(l)
 
 	switch strings.ToUpper(item) {
 	case ReservedCreate:
@@ -94,7 +157,46 @@ func (p *parser) doParse() (Stmt, error) {
 	case ReservedCreateOrReplace:
 		fallthrough
 	case ReservedReplace:
-		switch strings.ToUpper(p.pop(ReservedTable, ReservedStream)) {
+		kinds := []string{ReservedTable, ReservedStream, ReservedType, ReservedSourceConnector, ReservedSinkConnector}
+		kind, l := p.peekWithLength(kinds...)
+		if !arrayContains(kinds, strings.ToUpper(kind)) {
+			return nil, p.Error(strings.Join(kinds, " or "))
+		}
+		p.popLength(l)
+		switch kind := strings.ToUpper(kind); kind {
+		case ReservedSourceConnector, ReservedSinkConnector:
+			if item != ReservedCreate {
+				// connectors can't be replaced
+				return nil, p.Error(fmt.Sprintf("%s or %s", ReservedTable, ReservedStream))
+			}
+			return p.parseCreateConnector(item, ConnectorType(strings.Fields(kind)[0]))
+		case ReservedType:
+			if item != ReservedCreate {
+				// types can't be replaced
+				return nil, p.Error(fmt.Sprintf("%s or %s", ReservedTable, ReservedStream))
+			}
+			n, l := p.peekWithLength()
+			if l == 0 || !isIdentifier(n) {
+				return nil, p.Error("[name]")
+			}
+			p.popLength(l)
+			if _, err := p.popOrError(ReservedAs); err != nil {
+				return nil, err
+			}
+			def, err := p.parseDataType()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.popOrError(ReservedEndOfStatement); err != nil {
+				return nil, err
+			}
+			return &createTypeStmt{
+				stmt: stmt{
+					Type: StmtActionType(item),
+					Name: n,
+				},
+				Definition: def,
+			}, nil
 		case ReservedTable:
 			n := p.pop()
 			if len(n) == 0 {
@@ -229,15 +331,37 @@ func (p *parser) doParse() (Stmt, error) {
 			return stmt, nil
 
 		default:
-			return nil, p.Error(fmt.Sprintf("%s or %s", ReservedTable, ReservedStream))
+			return nil, p.Error(fmt.Sprintf("%s or %s or %s or %s", ReservedTable, ReservedStream, ReservedType, ReservedConnector))
 		}
 
+	case ReservedDrop:
+		if _, err := p.popOrError(ReservedConnector); err != nil {
+			return nil, err
+		}
+		return p.parseDropConnector()
+	case ReservedDescribe:
+		if _, err := p.popOrError(ReservedConnector); err != nil {
+			return nil, err
+		}
+		return p.parseDescribeConnector()
+	case ReservedSet:
+		return p.parseSet()
+	case ReservedUnset:
+		return p.parseUnset()
+	case ReservedDefine:
+		return p.parseDefine()
+	case ReservedUndefine:
+		return p.parseUndefine()
+
 	case ReservedInsert:
 		// TODO
 		n := p.pop()
 		if len(n) == 0 {
 			return nil, p.Error("[name]")
 		}
+		if next, _ := p.peekWithLength(ReservedOpenParens, ReservedValues); next == ReservedOpenParens || next == ReservedValues {
+			return p.parseInsertValues(item, n)
+		}
 		stmt := &insertIntoStmt{
 			stmt: stmt{
 				Type: StmtActionType(item),
@@ -253,7 +377,7 @@ func (p *parser) doParse() (Stmt, error) {
 		_, err = p.popOrError(ReservedEndOfStatement)
 		return stmt, nil
 	default:
-		return nil, p.Error(fmt.Sprintf("%s or %s or %s or %s", ReservedCreate, ReservedCreateOrReplace, ReservedReplace, ReservedInsert))
+		return nil, p.Error(strings.Join(stmtKeywords, " or "))
 	}
 }
 
@@ -3,6 +3,12 @@ package ksqlparser
 type stmt struct {
 	Type StmtActionType
 	Name string
+	// Comments are the comments around the stmt and any which could not be attached to one of its nodes
+	Comments Comments
+}
+
+func (s *stmt) comments() *Comments {
+	return &s.Comments
 }
 
 type StmtActionType string
@@ -12,17 +18,33 @@ const (
 	StmtTypeSelect = StmtActionType(ReservedSelect)
 	// StmtTypeInsert represents an INSERT stmt
 	StmtTypeInsert = StmtActionType(ReservedInsert)
+	// StmtTypeInsertValues represents an INSERT INTO ... VALUES stmt
+	StmtTypeInsertValues = StmtActionType(ReservedInsert + " " + ReservedValues)
 	// StmtTypeCreate represents an CREATE stmt
 	StmtTypeCreate = StmtActionType(ReservedCreate)
 	// StmtTypeCreateOrReplace represents an CREATE OR REPLACE stmt
 	StmtTypeCreateOrReplace = StmtActionType(ReservedCreateOrReplace)
 	// StmtTypeReplace represents an REPLACE stmt
 	StmtTypeReplace = StmtActionType(ReservedReplace)
+	// StmtTypeDrop represents a DROP stmt
+	StmtTypeDrop = StmtActionType(ReservedDrop)
+	// StmtTypeDescribe represents a DESCRIBE stmt
+	StmtTypeDescribe = StmtActionType(ReservedDescribe)
+	// StmtTypeSet represents a SET stmt
+	StmtTypeSet = StmtActionType(ReservedSet)
+	// StmtTypeUnset represents an UNSET stmt
+	StmtTypeUnset = StmtActionType(ReservedUnset)
+	// StmtTypeDefine represents a DEFINE stmt
+	StmtTypeDefine = StmtActionType(ReservedDefine)
+	// StmtTypeUndefine represents an UNDEFINE stmt
+	StmtTypeUndefine = StmtActionType(ReservedUndefine)
 )
 
 type CreateObjectType string
 
 const (
-	CreateObjectTypeTable  = CreateObjectType(ReservedTable)
-	CreateObjectTypeStream = CreateObjectType(ReservedStream)
+	CreateObjectTypeTable     = CreateObjectType(ReservedTable)
+	CreateObjectTypeStream    = CreateObjectType(ReservedStream)
+	CreateObjectTypeType      = CreateObjectType(ReservedType)
+	CreateObjectTypeConnector = CreateObjectType(ReservedConnector)
 )
@@ -8,6 +8,7 @@ import (
 type tableSelect struct {
 	Expressions aliasedExpressions
 	Identifier  identifier
+	Joins       *[]joinExpression
 	Window      *WindowExpression
 	Where       *[]*Condition
 	Group       []*aliasedExpression
@@ -18,6 +19,11 @@ func (s *tableSelect) String() string {
 	var sb []string
 	sb = append(sb, s.Expressions.String())
 	sb = append(sb, fmt.Sprintf("%s%s", StringOptions.fromPrefix, ReservedFrom), s.Identifier.String())
+	if s.Joins != nil {
+		for _, j := range *s.Joins {
+			sb = append(sb, fmt.Sprintf("%s%s", StringOptions.joinPrefix, j.String()))
+		}
+	}
 
 	if s.Window != nil {
 		sb = append(sb, fmt.Sprintf("%s%s", StringOptions.windowPrefix, ReservedWindow), s.Window.String())
@@ -74,10 +80,22 @@ func (p *parser) parseTableSelect() (*tableSelect, error) {
 	}
 	result.Identifier = *i
 
-	item, l, err := p.peekWithLengthOrError(ReservedWindow, ReservedWhere, ReservedGroupBy, ReservedHaving, ReservedEmit, ReservedEndOfStatement)
+	item, l, err := p.peekWithLengthOrError(ReservedLeftJoin, ReservedWindow, ReservedWhere, ReservedGroupBy, ReservedHaving, ReservedEmit, ReservedEndOfStatement)
 	if err != nil {
 		return nil, err
 	}
+	if item == ReservedLeftJoin {
+		p.popLength(l)
+		j, err := p.parseJoin()
+		if err != nil {
+			return nil, err
+		}
+		result.Joins = j
+		item, l, err = p.peekWithLengthOrError(ReservedWindow, ReservedWhere, ReservedGroupBy, ReservedHaving, ReservedEmit, ReservedEndOfStatement)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if item == ReservedWindow {
 		p.popLength(l)
 		w, err := p.parseWindow()
@@ -32,16 +32,24 @@ func (p *parser) popLength(len int) {
 	p.popWhitespace()
 }
 
+// popWhitespace consumes the whitespace and comments following a token, recording the comments
 func (p *parser) popWhitespace() {
+	p.newline = false
+	p.skipWhitespace()
+}
+
+func (p *parser) skipWhitespace() {
 	for ; p.i < len(p.sql) && isWhitespaceRune(rune(p.sql[p.i])); p.i++ {
 		if p.sql[p.i] == '\n' {
 			p.line++
 			p.col = -1
+			p.newline = true
 		}
 		p.col++
 	}
 	// check for multiline comment start
 	if "/*" == p.sql[p.i:min(len(p.sql), p.i+2)] {
+		start := p.i
 		p.col += 2
 		for p.i += 2; p.i < len(p.sql); p.i++ {
 			if "*/" == strings.ToUpper(p.sql[p.i:min(len(p.sql), p.i+2)]) {
@@ -55,11 +63,13 @@ func (p *parser) popWhitespace() {
 			}
 			p.col++
 		}
+		p.comments = append(p.comments, comment{Text: p.sql[start:min(len(p.sql), p.i)], OwnLine: p.newline})
 		//ensure that were out of whitespace
-		p.popWhitespace()
+		p.skipWhitespace()
 	}
 	// check for singleline comment
 	if "--" == p.sql[p.i:min(len(p.sql), p.i+2)] {
+		start := p.i
 		p.col += 2
 		for p.i += 2; p.i < len(p.sql); p.i++ {
 			if p.sql[p.i] == '\n' {
@@ -69,7 +79,8 @@ func (p *parser) popWhitespace() {
 			}
 			p.col++
 		}
-		p.popWhitespace()
+		p.comments = append(p.comments, comment{Text: strings.TrimSpace(p.sql[start:p.i]), OwnLine: p.newline})
+		p.skipWhitespace()
 	}
 }
 
@@ -114,6 +125,15 @@ func (p *parser) peekQuotedStringWithLength() (string, int) {
 	return "", 0
 }
 
+// peekQuotedIdentifierWithLength peeks a ` or " quoted identifier, the quotes are kept
+func (p *parser) peekQuotedIdentifierWithLength() (string, int) {
+	if p.i >= len(p.sql) || (p.sql[p.i] != '`' && p.sql[p.i] != '"') {
+		return "", 0
+	}
+	end := quotedEnd(p.sql, p.i)
+	return p.sql[p.i:end], end - p.i
+}
+
 func (p *parser) peekIdentifierWithLength() (string, int) {
 	for i := p.i; i < len(p.sql); i++ {
 		snip := p.sql[i:min(len(p.sql), i+2)]
@@ -35,6 +35,13 @@ func min(a, b int) int {
 	return b
 }
 
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func isWhitespaceRune(c rune) bool {
 	return c == ' ' || c == '\n' || c == '\r' || c == '\t'
 }
@@ -45,7 +52,9 @@ func isIdentifierRune(c rune) bool {
 		('0' <= c && c <= '9') ||
 		('_' == c) ||
 		('.' == c) ||
-		('*' == c)
+		('*' == c) ||
+		// a ${variable} which hasn't been substituted
+		('$' == c) || ('{' == c) || ('}' == c)
 }
 
 func arrayContains(array []string, contains string) bool {
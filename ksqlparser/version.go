@@ -0,0 +1,29 @@
+package ksqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VersionName appends _V<version> to name, quoted names are versioned inside their quotes
+func VersionName(name string, version int) string {
+	return renameIdentifier(name, func(name string) string {
+		return fmt.Sprintf("%s_V%d", name, version)
+	})
+}
+
+// VersionTopic appends _v<version> to the KAFKA_TOPIC of a CREATE STREAM or CREATE TABLE stmt so that a new version of
+// it doesn't write to the topic of the old one. A stmt without KAFKA_TOPIC is left alone as its topic is named after it.
+func VersionTopic(stmt Stmt, version int) {
+	var w *with
+	switch s := stmt.(type) {
+	case *createStreamStmt:
+		w = s.With
+	case *createTableStmt:
+		w = s.With
+	}
+	if w == nil || w.KafkaTopic == "" {
+		return
+	}
+	w.KafkaTopic = fmt.Sprintf("'%s_v%d'", strings.Trim(w.KafkaTopic, "'"), version)
+}
@@ -0,0 +1,53 @@
+package ksqlparser
+
+import (
+	"testing"
+)
+
+func TestVersionName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "PAGEVIEWS", want: "PAGEVIEWS_V2"},
+		{name: "`pageviews`", want: "`pageviews_V2`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VersionName(tt.name, 2); got != tt.want {
+				t.Errorf("VersionName() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionTopic(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "topic",
+			sql:  "CREATE TABLE a WITH (KAFKA_TOPIC='a') AS SELECT id, COUNT(*) FROM b GROUP BY id;",
+			want: "CREATE TABLE a WITH (KAFKA_TOPIC='a_v3') AS SELECT id, COUNT(*) FROM b GROUP BY id;",
+		},
+		{
+			name: "no topic",
+			sql:  "CREATE STREAM a AS SELECT * FROM b EMIT CHANGES;",
+			want: "CREATE STREAM a AS SELECT * FROM b EMIT CHANGES;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			VersionTopic(stmts[0], 3)
+			if got := Normalise(stmts[0].String()); got != Normalise(tt.want) {
+				t.Errorf("VersionTopic() got = %s, want %s", got, Normalise(tt.want))
+			}
+		})
+	}
+}
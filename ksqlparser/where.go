@@ -3,7 +3,14 @@ package ksqlparser
 func (p *parser) parseConditions() ([]*Condition, error) {
 	var result []*Condition
 	for {
-		condition := Condition{}
+		if len(result) > 0 {
+			// a comment after the conjunction belongs to the previous condition
+			prev := result[len(result)-1]
+			prev.Comments.Trailing = append(prev.Comments.Trailing, p.trailingComments()...)
+		}
+		condition := Condition{
+			Comments: Comments{Leading: p.leadingComments()},
+		}
 		le, err := p.parseExpression()
 		if err != nil {
 			return nil, err
@@ -19,6 +26,7 @@ func (p *parser) parseConditions() ([]*Condition, error) {
 		condition.Operand1 = le
 		condition.Operator = Operator(o)
 		condition.Operand2 = re
+		condition.Comments.Trailing = p.trailingComments()
 		result = append(result, &condition)
 		i, l := p.peekWithLength(ReservedAnd, ReservedOr)
 		switch i {
@@ -41,25 +41,28 @@ type with struct {
 
 func (w *with) String() string {
 	var sb []string
-
-	sb = append(sb, fmt.Sprintf("%s %s %s", WithPropertyKafkaTopic, ReservedEq, w.KafkaTopic))
-
+	add := func(name, value string) {
+		sb = append(sb, fmt.Sprintf("%s %s %s", name, ReservedEq, value))
+	}
+	if w.KafkaTopic != "" {
+		add(WithPropertyKafkaTopic, w.KafkaTopic)
+	}
 	if string(w.ValueFormat) != "" {
-		sb = append(sb, fmt.Sprintf("%s%s %s %s", ReservedComma, WithPropertyValueFormat, ReservedEq, w.ValueFormat))
+		add(WithPropertyValueFormat, string(w.ValueFormat))
 	}
 	if w.Key != "" {
-		sb = append(sb, fmt.Sprintf("%s%s %s %s", ReservedComma, WithPropertyKey, ReservedEq, w.Key))
+		add(WithPropertyKey, w.Key)
 	}
 	if w.TimeStamp != "" {
-		sb = append(sb, fmt.Sprintf("%s%s %s %s", ReservedComma, WithPropertyTimeStamp, ReservedEq, w.TimeStamp))
+		add(WithPropertyTimeStamp, w.TimeStamp)
 	}
 	if w.Replicas > 0 {
-		sb = append(sb, fmt.Sprintf("%s%s %s %s", ReservedComma, WithPropertyReplicas, ReservedEq, strconv.Itoa(w.Replicas)))
+		add(WithPropertyReplicas, strconv.Itoa(w.Replicas))
 	}
 	if w.Partitions > 0 {
-		sb = append(sb, fmt.Sprintf("%s%s %s %s", ReservedComma, WithPropertyPartitions, ReservedEq, strconv.Itoa(w.Partitions)))
+		add(WithPropertyPartitions, strconv.Itoa(w.Partitions))
 	}
-	return strings.Join(sb, " ")
+	return strings.Join(sb, " "+ReservedComma)
 }
 
 func (p *parser) parseWith(withProperties ...string) (*with, error) {
@@ -129,3 +132,68 @@ func (p *parser) parseWith(withProperties ...string) (*with, error) {
 		}
 	}
 }
+
+// defaultableProperties are the WITH properties SetDefaultProperties can set
+var defaultableProperties = []string{
+	WithPropertyPartitions,
+	WithPropertyReplicas,
+	WithPropertyValueFormat,
+}
+
+// SetDefaultProperties sets each of the WITH properties in defaults on the CREATE STREAM and CREATE TABLE statements
+// in stmts which don't already have it. VALUE_FORMAT is set on every statement, PARTITIONS and REPLICAS are only set
+// on statements with a select as they create their topic. Only these properties may be defaulted, values are given
+// unquoted.
+func SetDefaultProperties(stmts []Stmt, defaults map[string]string) error {
+	var valueFormat WithValueFormat
+	var partitions, replicas int
+	for prop, value := range defaults {
+		var err error
+		switch strings.ToUpper(prop) {
+		case WithPropertyValueFormat:
+			valueFormat = WithValueFormat(fmt.Sprintf("'%s'", strings.ToUpper(strings.Trim(value, "'"))))
+			if !arrayContains([]string{ValueFormatAvro, ValueFormatJson, ValueFormatDelimited}, string(valueFormat)) {
+				return fmt.Errorf("unsupported %s %s", WithPropertyValueFormat, value)
+			}
+		case WithPropertyPartitions:
+			partitions, err = strconv.Atoi(value)
+		case WithPropertyReplicas:
+			replicas, err = strconv.Atoi(value)
+		default:
+			return fmt.Errorf("%s can't be defaulted, only %s", prop, strings.Join(defaultableProperties, ", "))
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s %s: %v", prop, value, err)
+		}
+	}
+
+	for _, stmt := range stmts {
+		var w **with
+		var selects bool
+		switch s := stmt.(type) {
+		case *createStreamStmt:
+			w, selects = &s.With, s.Select != nil
+		case *createTableStmt:
+			w, selects = &s.With, s.Select != nil
+		default:
+			continue
+		}
+		result := with{}
+		if *w != nil {
+			result = **w
+		}
+		if result.ValueFormat == "" {
+			result.ValueFormat = valueFormat
+		}
+		if selects && result.Partitions == 0 {
+			result.Partitions = partitions
+		}
+		if selects && result.Replicas == 0 {
+			result.Replicas = replicas
+		}
+		if *w != nil || result != (with{}) {
+			*w = &result
+		}
+	}
+	return nil
+}
@@ -37,6 +37,13 @@ var (
 	KSQLBaseURL  string
 	KSQLUsername string
 	KSQLPassword string
+	publishGraph bool
+	namingPolicy string
+
+	webhookAddr     string
+	webhookCertFile string
+	webhookKeyFile  string
+	webhookPolicies bool
 )
 
 func main() {
@@ -71,14 +78,33 @@ func main() {
 	controller := NewController(kubeClientSet,
 		mgazzaClientSet,
 		mgazzaInformerFactory.Mgazza().V1alpha1().ManagedKSQLs(),
+		mgazzaInformerFactory.Mgazza().V1alpha1().KSQLPolicies(),
+		informerFactory.Core().V1().ConfigMaps(),
+		informerFactory.Core().V1().Secrets(),
+		informerFactory.Core().V1().Namespaces(),
 		ksqlClient,
 	)
+	controller.publishGraph = publishGraph
+	controller.defaultNamingPolicy = namingPolicy
 
 	// notice that there is no need to run Start methods in a separate goroutine. (i.e. go kubeInformerFactory.Start(stopCh)
 	// Start method is non-blocking and runs all registered informers in a dedicated goroutine.
 	mgazzaInformerFactory.Start(stopCh)
 	informerFactory.Start(stopCh)
 
+	if webhookAddr != "" {
+		wh := &webhook{controller: controller, validatePolicies: webhookPolicies}
+		go func() {
+			// the webhooks read from the listers so they aren't served until the caches have synced
+			if !controller.waitForCacheSync(stopCh) {
+				return
+			}
+			if err := wh.Serve(webhookAddr, webhookCertFile, webhookKeyFile, stopCh); err != nil {
+				klog.Fatalf("Error serving webhooks: %s", err.Error())
+			}
+		}()
+	}
+
 	if err = controller.Run(2, stopCh); err != nil {
 		klog.Fatalf("Error running controller: %s", err.Error())
 	}
@@ -98,4 +124,10 @@ func init() {
 	flag.StringVar(&KSQLBaseURL, "baseURL", envOrDefault("KSQL_URL", "http://ksqldb-server:8088"), "The Base URL of the ksql server")
 	flag.StringVar(&KSQLUsername, "username", envOrDefault("KSQL_USERNAME", ""), "The Username for use with the ksql server")
 	flag.StringVar(&KSQLPassword, "password", envOrDefault("KSQL_PASSWORD", ""), "The Password for use with the ksql server")
+	flag.BoolVar(&publishGraph, "publishGraph", false, "Publish the dependency graph of each ManagedKSQL to a <name>-graph ConfigMap")
+	flag.StringVar(&namingPolicy, "namingPolicy", "", "Template for the names of streams and tables e.g. {{.Namespace}}_{{.Name}}, overridden by the "+namingPolicyAnnotation+" annotation of a Namespace")
+	flag.StringVar(&webhookAddr, "webhookAddr", "", "The address to serve the admission webhooks on e.g. :8443, the webhooks are disabled when empty")
+	flag.StringVar(&webhookCertFile, "webhookCertFile", "/etc/webhook/certs/tls.crt", "The TLS certificate of the admission webhooks, reloaded when it changes")
+	flag.StringVar(&webhookKeyFile, "webhookKeyFile", "/etc/webhook/certs/tls.key", "The TLS key of the admission webhooks")
+	flag.BoolVar(&webhookPolicies, "webhookPolicies", true, "Reject ManagedKSQLs which break a KSQLPolicy in the admission webhook")
 }
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"ksql_operator/ksqlclient"
+	"ksql_operator/ksqlclient/swagger"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+const (
+	// defaultCatchUp is how long the new version of a stream or table runs before the statements reading from it are
+	// moved over when a ManagedKSQL doesn't set catchUpSeconds
+	defaultCatchUp = time.Minute
+	// migrationPollInterval is how often a new version which has run for long enough is checked again
+	migrationPollInterval = 10 * time.Second
+	// queryStateRunning is the state ksqlDB explains a running query with
+	queryStateRunning = "RUNNING"
+)
+
+const (
+	// errMigrationStarted stops applying the statements of a resource once a migration has started, they're applied
+	// again with the new version when the status change is seen
+	errMigrationStarted = Error("migration started")
+)
+
+// catchUp is how long managedKSQL runs the new version of a stream or table before moving its readers over
+func catchUp(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) time.Duration {
+	if managedKSQL.CatchUpSeconds == nil {
+		return defaultCatchUp
+	}
+	return time.Duration(*managedKSQL.CatchUpSeconds) * time.Second
+}
+
+// startMigration starts migrating the stream or table declared by stmt to a new version when ksqlDB has rejected
+// replacing it and managedKSQL has the BlueGreen strategy, it reports whether it did. Only a stream or table with a
+// query can be migrated and only once its last migration is complete, otherwise it's dropped and created again.
+func startMigration(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, stmt ksqlparser.Stmt, commandStatus *ksqloperatorv1alpha1.CommandStatus) bool {
+	if managedKSQL.UpgradeStrategy != ksqloperatorv1alpha1.UpgradeStrategyBlueGreen {
+		return false
+	}
+	upgrade := commandStatus.Upgrade
+	if upgrade == nil || upgrade.Outcome != ksqloperatorv1alpha1.UpgradeRejected || upgrade.QuerySha != StmtHasher(stmt.String()) {
+		return false
+	}
+	if _, ok := ksqlparser.OrReplace(stmt); !ok || len(stmt.GetDataSources()) == 0 {
+		return false
+	}
+
+	base := baseName(managedKSQL, stmt.GetName())
+	if _, ok := managedKSQL.Status.Migrations[base]; ok {
+		return false
+	}
+	version := 2
+	if v, ok := managedKSQL.Status.Versions[base]; ok {
+		version = v + 1
+	}
+	if managedKSQL.Status.Migrations == nil {
+		managedKSQL.Status.Migrations = map[string]ksqloperatorv1alpha1.Migration{}
+	}
+	managedKSQL.Status.Migrations[base] = ksqloperatorv1alpha1.Migration{
+		Version:   version,
+		Name:      ksqlparser.VersionName(base, version),
+		Previous:  stmt.GetName(),
+		Phase:     ksqloperatorv1alpha1.MigrationCatchingUp,
+		StartTime: metav1.Now(),
+	}
+	return true
+}
+
+// baseName is the name before it was versioned of the stream or table managedKSQL has on the ksqlDB server as name
+func baseName(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, name string) string {
+	for base, migration := range managedKSQL.Status.Migrations {
+		if migration.Name == name || migration.Previous == name {
+			return base
+		}
+	}
+	for base, version := range managedKSQL.Status.Versions {
+		if ksqlparser.VersionName(base, version) == name {
+			return base
+		}
+	}
+	return name
+}
+
+// versionsKey changes whenever versionStatements would rename the statements of managedKSQL differently
+func versionsKey(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) string {
+	var keys []string
+	for base, version := range managedKSQL.Status.Versions {
+		keys = append(keys, fmt.Sprintf("%s=%d", base, version))
+	}
+	for base, migration := range managedKSQL.Status.Migrations {
+		keys = append(keys, fmt.Sprintf("%s=%d/%s", base, migration.Version, migration.Phase))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// versionStatements renames the streams and tables of stmts which have been migrated to the version in use. While a
+// new version catches up only the statement declaring it is renamed to it, the statements reading from or inserting
+// into it are renamed once it has caught up. It returns names and props updated with the new names.
+func versionStatements(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, stmts []ksqlparser.Stmt, names map[string]string,
+	props map[string]map[string]string) (map[string]string, map[string]map[string]string) {
+	oldNames := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		oldNames[i] = stmt.GetName()
+	}
+
+	bases := map[string]bool{}
+	for base := range managedKSQL.Status.Versions {
+		bases[base] = true
+	}
+	for base := range managedKSQL.Status.Migrations {
+		bases[base] = true
+	}
+	switched := map[string]string{}
+	for _, base := range sortedKeys(bases) {
+		version := managedKSQL.Status.Versions[base]
+		latest := version
+		migration, migrating := managedKSQL.Status.Migrations[base]
+		if migrating {
+			latest = migration.Version
+		}
+		for _, stmt := range stmts {
+			if stmt.GetName() == base {
+				ksqlparser.Rename([]ksqlparser.Stmt{stmt}, versionRenamer(base, latest))
+				ksqlparser.VersionTopic(stmt, latest)
+			}
+		}
+		if migrating && migration.Phase == ksqloperatorv1alpha1.MigrationCatchingUp {
+			// the readers stay with the version in use until the new one has caught up
+			latest = version
+		}
+		if latest == 0 {
+			continue
+		}
+		ksqlparser.Rename(stmts, versionRenamer(base, latest))
+		switched[base] = ksqlparser.VersionName(base, latest)
+	}
+
+	versionedNames := map[string]string{}
+	for written, name := range names {
+		if versioned, ok := switched[name]; ok {
+			name = versioned
+		}
+		versionedNames[written] = name
+	}
+	versionedProps := map[string]map[string]string{}
+	for i, stmt := range stmts {
+		if p, ok := props[oldNames[i]]; ok {
+			versionedProps[stmt.GetName()] = p
+		}
+	}
+	return versionedNames, versionedProps
+}
+
+// versionRenamer renames base to its version
+func versionRenamer(base string, version int) func(string) string {
+	versioned := unquoteIdentifier(ksqlparser.VersionName(base, version))
+	return func(name string) string {
+		if strings.EqualFold(name, unquoteIdentifier(base)) {
+			return versioned
+		}
+		return name
+	}
+}
+
+// unquoteIdentifier is name without the quotes around it
+func unquoteIdentifier(name string) string {
+	if len(name) > 1 && (name[0] == '`' || name[0] == '"') && name[len(name)-1] == name[0] {
+		return name[1 : len(name)-1]
+	}
+	return name
+}
+
+// versionNames are the names on the ksqlDB server of the stream or table managedKSQL declares as name, both the old
+// and new versions while it's being migrated
+func versionNames(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, name string) []string {
+	base := baseName(managedKSQL, name)
+	migration, ok := managedKSQL.Status.Migrations[base]
+	if !ok {
+		return []string{name}
+	}
+	names := []string{migration.Name}
+	if migration.Previous != "" {
+		names = append(names, migration.Previous)
+	}
+	return names
+}
+
+// retiring are the names of the old versions which must be kept while their new versions catch up
+func retiring(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) map[string]bool {
+	result := map[string]bool{}
+	for _, migration := range managedKSQL.Status.Migrations {
+		if migration.Phase == ksqloperatorv1alpha1.MigrationCatchingUp {
+			result[migration.Previous] = true
+		}
+	}
+	return result
+}
+
+// foreignReaders returns the queries which read from or write to the old version of each migration which is
+// switching and which aren't run by managedKSQL, keyed by the old version. They belong to other resources, or were
+// run by hand, and would be terminated if it were dropped.
+func (c *Controller) foreignReaders(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) (map[string][]string, error) {
+	own := map[string]bool{}
+	for _, commandStatus := range managedKSQL.Status.ItemStatus {
+		if commandStatus.QueryID != "" {
+			own[commandStatus.QueryID] = true
+		}
+	}
+	result := map[string][]string{}
+	for _, migration := range managedKSQL.Status.Migrations {
+		if migration.Phase != ksqloperatorv1alpha1.MigrationSwitching || migration.Previous == "" {
+			continue
+		}
+		resp, err := c.ksqlClient.Describe(context.Background(), migration.Previous)
+		if err != nil {
+			return nil, fmt.Errorf("error describing %s: %v", migration.Previous, err)
+		}
+		switch resp := resp.(type) {
+		case *swagger.ModelError:
+			if resp.ErrorCode == ksqlclient.ErrCodeNotFound {
+				continue
+			}
+			return nil, fmt.Errorf("error response from ksql: (%0f) %s", resp.ErrorCode, resp.Message)
+		case *[]swagger.DescribeResultItem:
+			for _, item := range *resp {
+				if item.SourceDescription == nil {
+					continue
+				}
+				for _, queries := range [][]swagger.DescribeResultItemSourceDescriptionQuery{
+					item.SourceDescription.ReadQueries, item.SourceDescription.WriteQueries} {
+					for _, q := range queries {
+						if !own[q.Id] {
+							result[migration.Previous] = append(result[migration.Previous], q.Id)
+						}
+					}
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unexpected result type %t", resp)
+		}
+	}
+	return result, nil
+}
+
+// advanceMigrations moves the statements reading from a stream or table over to its new version once it has run for
+// long enough and its query is running
+func (c *Controller) advanceMigrations(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) error {
+	var bases []string
+	for base := range managedKSQL.Status.Migrations {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+	for _, base := range bases {
+		migration := managedKSQL.Status.Migrations[base]
+		if migration.Phase != ksqloperatorv1alpha1.MigrationCatchingUp || time.Since(migration.StartTime.Time) < catchUp(managedKSQL) {
+			continue
+		}
+		running, err := c.queryRunning(managedKSQL.Status.ItemStatus[migration.Name].QueryID)
+		if err != nil {
+			return fmt.Errorf("error checking %s has caught up: %v", migration.Name, err)
+		}
+		if running {
+			migration.Phase = ksqloperatorv1alpha1.MigrationSwitching
+			managedKSQL.Status.Migrations[base] = migration
+		}
+	}
+	return nil
+}
+
+// queryRunning reports whether ksqlDB explains the query queryID as running
+func (c *Controller) queryRunning(queryID string) (bool, error) {
+	if queryID == "" {
+		return false, nil
+	}
+	result, err := c.ksqlClient.Explain(context.Background(), queryID)
+	if err != nil {
+		return false, err
+	}
+	switch result := result.(type) {
+	case *swagger.ModelError:
+		return false, fmt.Errorf("error response from ksql: (%0f) %s", result.ErrorCode, result.Message)
+	case *[]swagger.ExplainResultItem:
+		return len(*result) == 1 && (*result)[0].QueryDescription != nil &&
+			(*result)[0].QueryDescription.State == queryStateRunning, nil
+	default:
+		return false, fmt.Errorf("unexpected result type %t", result)
+	}
+}
+
+// completeMigrations removes the migrations whose old version has been dropped, recording the version now in use
+func completeMigrations(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) {
+	for base, migration := range managedKSQL.Status.Migrations {
+		if migration.Phase != ksqloperatorv1alpha1.MigrationSwitching {
+			continue
+		}
+		if _, ok := managedKSQL.Status.ItemStatus[migration.Previous]; ok {
+			continue
+		}
+		delete(managedKSQL.Status.Migrations, base)
+		if managedKSQL.Status.Versions == nil {
+			managedKSQL.Status.Versions = map[string]int{}
+		}
+		managedKSQL.Status.Versions[base] = migration.Version
+		if commandStatus, ok := managedKSQL.Status.ItemStatus[migration.Name]; ok {
+			commandStatus.Upgrade = &ksqloperatorv1alpha1.UpgradeStatus{
+				Outcome:  ksqloperatorv1alpha1.UpgradeMigrated,
+				QuerySha: commandStatus.QuerySha,
+			}
+			managedKSQL.Status.ItemStatus[migration.Name] = commandStatus
+		}
+	}
+}
+
+// migrationRequeue is how long until a migration catching up, or one whose old version is kept for the queries of
+// others, should be checked again, 0 when there's none
+func migrationRequeue(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) time.Duration {
+	var requeue time.Duration
+	for _, migration := range managedKSQL.Status.Migrations {
+		var remaining time.Duration
+		switch {
+		case migration.Phase == ksqloperatorv1alpha1.MigrationCatchingUp:
+			remaining = catchUp(managedKSQL) - time.Since(migration.StartTime.Time)
+		case migration.Phase == ksqloperatorv1alpha1.MigrationSwitching && migration.Previous != "":
+			// nothing tells us when the queries of other resources stop reading it
+			remaining = migrationPollInterval
+		default:
+			continue
+		}
+		if remaining < migrationPollInterval {
+			remaining = migrationPollInterval
+		}
+		if requeue == 0 || remaining < requeue {
+			requeue = remaining
+		}
+	}
+	return requeue
+}
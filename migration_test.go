@@ -0,0 +1,244 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"ksql_operator/ksqlclient/swagger"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+func TestRetiring(t *testing.T) {
+	managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{Status: ksqloperatorv1alpha1.ManagedKSQLStatus{
+		Migrations: map[string]ksqloperatorv1alpha1.Migration{
+			"A": {Version: 2, Name: "A_V2", Previous: "A", Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+			"B": {Version: 3, Name: "B_V3", Previous: "B_V2", Phase: ksqloperatorv1alpha1.MigrationSwitching},
+		},
+	}}
+	if diff := deep.Equal(retiring(managedKSQL), map[string]bool{"A": true}); diff != nil {
+		t.Errorf("retiring() got = %v", diff)
+	}
+}
+
+func TestAdvanceMigrations(t *testing.T) {
+	caughtUp := metav1.NewTime(time.Now().Add(-2 * defaultCatchUp))
+	tests := []struct {
+		name      string
+		migration ksqloperatorv1alpha1.Migration
+		state     string
+		want      ksqloperatorv1alpha1.MigrationPhase
+	}{
+		{
+			name:      "caught up",
+			migration: ksqloperatorv1alpha1.Migration{StartTime: caughtUp, Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+			state:     queryStateRunning,
+			want:      ksqloperatorv1alpha1.MigrationSwitching,
+		},
+		{
+			name:      "too soon",
+			migration: ksqloperatorv1alpha1.Migration{StartTime: metav1.Now(), Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+			state:     queryStateRunning,
+			want:      ksqloperatorv1alpha1.MigrationCatchingUp,
+		},
+		{
+			name:      "not running",
+			migration: ksqloperatorv1alpha1.Migration{StartTime: caughtUp, Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+			state:     "ERROR",
+			want:      ksqloperatorv1alpha1.MigrationCatchingUp,
+		},
+		{
+			name:      "switching",
+			migration: ksqloperatorv1alpha1.Migration{StartTime: caughtUp, Phase: ksqloperatorv1alpha1.MigrationSwitching},
+			state:     "ERROR",
+			want:      ksqloperatorv1alpha1.MigrationSwitching,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migration := tt.migration
+			migration.Version = 2
+			migration.Name = "A_V2"
+			managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{Status: ksqloperatorv1alpha1.ManagedKSQLStatus{
+				ItemStatus: map[string]ksqloperatorv1alpha1.CommandStatus{"A_V2": {QueryID: "CSAS_A_V2_1"}},
+				Migrations: map[string]ksqloperatorv1alpha1.Migration{"A": migration},
+			}}
+			c := &Controller{ksqlClient: &fakeKSQLClient{states: map[string]string{"CSAS_A_V2_1": tt.state}}}
+			if err := c.advanceMigrations(managedKSQL); err != nil {
+				t.Fatal(err)
+			}
+			if got := managedKSQL.Status.Migrations["A"].Phase; got != tt.want {
+				t.Errorf("advanceMigrations() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionNames(t *testing.T) {
+	managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{Status: ksqloperatorv1alpha1.ManagedKSQLStatus{
+		Migrations: map[string]ksqloperatorv1alpha1.Migration{
+			"A": {Version: 2, Name: "A_V2", Previous: "A", Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+			"C": {Version: 3, Name: "C_V3", Previous: "C_V2", Phase: ksqloperatorv1alpha1.MigrationSwitching},
+		},
+		Versions: map[string]int{"B": 2, "C": 2},
+	}}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "A", want: []string{"A_V2", "A"}},
+		{name: "B_V2", want: []string{"B_V2"}},
+		{name: "C_V3", want: []string{"C_V3", "C_V2"}},
+		{name: "D", want: []string{"D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(versionNames(managedKSQL, tt.name), tt.want); diff != nil {
+				t.Errorf("versionNames() got = %v", diff)
+			}
+		})
+	}
+}
+
+func TestForeignReaders(t *testing.T) {
+	managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{Status: ksqloperatorv1alpha1.ManagedKSQLStatus{
+		ItemStatus: map[string]ksqloperatorv1alpha1.CommandStatus{
+			"A":    {QueryID: "CTAS_A_1"},
+			"A_V2": {QueryID: "CTAS_A_V2_2"},
+			"B":    {QueryID: "CSAS_B_3"},
+			"C":    {QueryID: "CTAS_C_5"},
+		},
+		Migrations: map[string]ksqloperatorv1alpha1.Migration{
+			"A": {Version: 2, Name: "A_V2", Previous: "A", Phase: ksqloperatorv1alpha1.MigrationSwitching},
+			"C": {Version: 2, Name: "C_V2", Previous: "C", Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+			"D": {Version: 2, Name: "D_V2", Previous: "D", Phase: ksqloperatorv1alpha1.MigrationSwitching},
+		},
+	}}
+	c := &Controller{ksqlClient: &fakeKSQLClient{sources: map[string]swagger.DescribeResultItemSourceDescription{
+		"A": {
+			ReadQueries:  []swagger.DescribeResultItemSourceDescriptionQuery{{Id: "CSAS_B_3"}, {Id: "CSAS_OTHER_4"}},
+			WriteQueries: []swagger.DescribeResultItemSourceDescriptionQuery{{Id: "CTAS_A_1"}},
+		},
+		"C": {ReadQueries: []swagger.DescribeResultItemSourceDescriptionQuery{{Id: "CSAS_OTHER_6"}}},
+	}}}
+	got, err := c.foreignReaders(managedKSQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(got, map[string][]string{"A": {"CSAS_OTHER_4"}}); diff != nil {
+		t.Errorf("foreignReaders() got = %v: %v", got, diff)
+	}
+}
+
+func TestVersionStatements(t *testing.T) {
+	sql := `
+CREATE TABLE a WITH (KAFKA_TOPIC='a') AS SELECT id, COUNT(*) AS n FROM src GROUP BY id;
+CREATE TABLE b AS SELECT * FROM a WHERE n > 1;`
+	tests := []struct {
+		name      string
+		status    ksqloperatorv1alpha1.ManagedKSQLStatus
+		want      string
+		wantNames map[string]string
+	}{
+		{
+			name: "catching up",
+			status: ksqloperatorv1alpha1.ManagedKSQLStatus{Migrations: map[string]ksqloperatorv1alpha1.Migration{
+				"a": {Version: 2, Name: "a_V2", Previous: "a", Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+			}},
+			want: `
+CREATE TABLE a_V2 WITH (KAFKA_TOPIC='a_v2') AS SELECT id, COUNT(*) AS n FROM src GROUP BY id;
+CREATE TABLE b AS SELECT * FROM a WHERE n > 1;`,
+			wantNames: map[string]string{"a": "a", "b": "b"},
+		},
+		{
+			name: "switching",
+			status: ksqloperatorv1alpha1.ManagedKSQLStatus{Migrations: map[string]ksqloperatorv1alpha1.Migration{
+				"a": {Version: 2, Name: "a_V2", Previous: "a", Phase: ksqloperatorv1alpha1.MigrationSwitching},
+			}},
+			want: `
+CREATE TABLE a_V2 WITH (KAFKA_TOPIC='a_v2') AS SELECT id, COUNT(*) AS n FROM src GROUP BY id;
+CREATE TABLE b AS SELECT * FROM a_V2 AS a WHERE n > 1;`,
+			wantNames: map[string]string{"a": "a_V2", "b": "b"},
+		},
+		{
+			name:   "migrated",
+			status: ksqloperatorv1alpha1.ManagedKSQLStatus{Versions: map[string]int{"a": 2}},
+			want: `
+CREATE TABLE a_V2 WITH (KAFKA_TOPIC='a_v2') AS SELECT id, COUNT(*) AS n FROM src GROUP BY id;
+CREATE TABLE b AS SELECT * FROM a_V2 AS a WHERE n > 1;`,
+			wantNames: map[string]string{"a": "a_V2", "b": "b"},
+		},
+		{
+			name: "migrating again",
+			status: ksqloperatorv1alpha1.ManagedKSQLStatus{
+				Migrations: map[string]ksqloperatorv1alpha1.Migration{
+					"a": {Version: 3, Name: "a_V3", Previous: "a_V2", Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+				},
+				Versions: map[string]int{"a": 2},
+			},
+			want: `
+CREATE TABLE a_V3 WITH (KAFKA_TOPIC='a_v3') AS SELECT id, COUNT(*) AS n FROM src GROUP BY id;
+CREATE TABLE b AS SELECT * FROM a_V2 AS a WHERE n > 1;`,
+			wantNames: map[string]string{"a": "a_V2", "b": "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := ksqlparser.Parse(sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ksqlparser.Parse(tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{Status: tt.status}
+			names, _ := versionStatements(managedKSQL, stmts, map[string]string{"a": "a", "b": "b"}, nil)
+			for i := range stmts {
+				if got := ksqlparser.Normalise(stmts[i].String()); got != ksqlparser.Normalise(want[i].String()) {
+					t.Errorf("versionStatements() got = %s, want %s", got, ksqlparser.Normalise(want[i].String()))
+				}
+			}
+			if diff := deep.Equal(names, tt.wantNames); diff != nil {
+				t.Errorf("versionStatements() names got = %v: %v", names, diff)
+			}
+		})
+	}
+}
+
+func TestCompleteMigrations(t *testing.T) {
+	managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{Status: ksqloperatorv1alpha1.ManagedKSQLStatus{
+		ItemStatus: map[string]ksqloperatorv1alpha1.CommandStatus{
+			"A_V2": {QuerySha: "a"},
+			"B":    {},
+			"B_V3": {},
+			"C_V2": {},
+		},
+		Migrations: map[string]ksqloperatorv1alpha1.Migration{
+			"A": {Version: 2, Name: "A_V2", Previous: "A", Phase: ksqloperatorv1alpha1.MigrationSwitching},
+			"B": {Version: 3, Name: "B_V3", Previous: "B", Phase: ksqloperatorv1alpha1.MigrationSwitching},
+			"C": {Version: 2, Name: "C_V2", Previous: "C", Phase: ksqloperatorv1alpha1.MigrationCatchingUp},
+		},
+	}}
+	completeMigrations(managedKSQL)
+	if diff := deep.Equal(sortedMigrations(managedKSQL), []string{"B", "C"}); diff != nil {
+		t.Errorf("completeMigrations() left %v", diff)
+	}
+	if diff := deep.Equal(managedKSQL.Status.Versions, map[string]int{"A": 2}); diff != nil {
+		t.Errorf("completeMigrations() versions %v", diff)
+	}
+	want := &ksqloperatorv1alpha1.UpgradeStatus{Outcome: ksqloperatorv1alpha1.UpgradeMigrated, QuerySha: "a"}
+	if diff := deep.Equal(managedKSQL.Status.ItemStatus["A_V2"].Upgrade, want); diff != nil {
+		t.Errorf("completeMigrations() upgrade %v", diff)
+	}
+}
+
+func sortedMigrations(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) []string {
+	bases := map[string]bool{}
+	for base := range managedKSQL.Status.Migrations {
+		bases[base] = true
+	}
+	return sortedKeys(bases)
+}
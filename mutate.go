@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+const (
+	// declaresAnnotation lists the streams and tables a ManagedKSQL declares
+	declaresAnnotation = "mgazza.github.com/declares"
+	// dependsOnAnnotation is a JSON object of the sources each stream and table of a ManagedKSQL reads from
+	dependsOnAnnotation = "mgazza.github.com/depends-on"
+	// declaresLabelPrefix is followed by the name of each stream and table a ManagedKSQL declares so that they can
+	// be found with a label selector
+	declaresLabelPrefix = "declares.mgazza.github.com/"
+)
+
+// defaultPropertyAnnotations on a Namespace give the WITH properties set on statements which don't have them
+var defaultPropertyAnnotations = map[string]string{
+	"mgazza.github.com/ksql-default-partitions":   ksqlparser.WithPropertyPartitions,
+	"mgazza.github.com/ksql-default-replicas":     ksqlparser.WithPropertyReplicas,
+	"mgazza.github.com/ksql-default-value-format": ksqlparser.WithPropertyValueFormat,
+}
+
+// jsonPatchOp is an operation of a JSON patch
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// mutate returns the JSON patch which sets the default WITH properties of the namespace of managedKSQL, formats its
+// statement and annotates and labels it with the streams and tables it and its statementFrom declare and their sources.
+// Names are those written in the statement rather than on the ksqlDB server.
+// old is the ManagedKSQL being updated or nil when it's created, defaults are only set when the statement is created
+// or changed so that those added to the namespace since don't change statements which are already running.
+func (wh *webhook) mutate(managedKSQL, old *ksqloperatorv1alpha1.ManagedKSQL) ([]jsonPatchOp, error) {
+	stmts, err := ksqlparser.Parse(managedKSQL.Statement)
+	if err != nil {
+		return nil, err
+	}
+
+	if old == nil || old.Statement != managedKSQL.Statement {
+		if err := wh.setDefaultProperties(managedKSQL.Namespace, stmts); err != nil {
+			return nil, err
+		}
+	}
+
+	var patch []jsonPatchOp
+	if statement := ksqlparser.Format(stmts, ksqlparser.DefaultFormatOptions); statement != managedKSQL.Statement {
+		patch = append(patch, jsonPatchOp{Op: "replace", Path: "/statement", Value: statement})
+	}
+	if len(managedKSQL.StatementFrom) > 0 {
+		// fragments are annotated but left as they're written
+		statement, err := wh.controller.statement(managedKSQL)
+		if err != nil {
+			return nil, err
+		}
+		if stmts, err = ksqlparser.Parse(statement); err != nil {
+			return nil, err
+		}
+	}
+
+	var declared []string
+	dependsOn := map[string][]string{}
+	for _, stmt := range stmts {
+		name := strings.ToUpper(stmt.GetName())
+		if _, ok := stmt.(ksqlparser.CreateStmt); ok {
+			declared = append(declared, name)
+		} else if t := stmt.GetActionType(); t == ksqlparser.StmtTypeInsert || t == ksqlparser.StmtTypeInsertValues {
+			// inserts are named by a hash, their sources belong to the stream they insert into
+			deps := ksqlparser.Dependencies(stmt)
+			name = strings.ToUpper(deps[len(deps)-1])
+		}
+		for _, source := range stmt.GetDataSources() {
+			if source = strings.ToUpper(source); !contains(dependsOn[name], source) {
+				dependsOn[name] = append(dependsOn[name], source)
+			}
+		}
+	}
+	for _, sources := range dependsOn {
+		sort.Strings(sources)
+	}
+	deps, err := json.Marshal(dependsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{}
+	for k, v := range managedKSQL.Annotations {
+		annotations[k] = v
+	}
+	annotations[declaresAnnotation] = strings.Join(declared, ",")
+	annotations[dependsOnAnnotation] = string(deps)
+
+	labels := map[string]string{}
+	for k, v := range managedKSQL.Labels {
+		// labels for streams and tables which are no longer declared are removed
+		if !strings.HasPrefix(k, declaresLabelPrefix) {
+			labels[k] = v
+		}
+	}
+	for _, name := range declared {
+		if key := declaresLabelPrefix + name; len(validation.IsQualifiedName(key)) == 0 {
+			labels[key] = ""
+		}
+	}
+
+	patch = append(patch,
+		jsonPatchOp{Op: "add", Path: "/metadata/annotations", Value: annotations},
+		jsonPatchOp{Op: "add", Path: "/metadata/labels", Value: labels},
+	)
+	return patch, nil
+}
+
+// setDefaultProperties sets the default WITH properties of namespace on stmts
+func (wh *webhook) setDefaultProperties(namespace string, stmts []ksqlparser.Stmt) error {
+	nsAnnotations, err := wh.controller.namespaceAnnotations(namespace)
+	if err != nil {
+		return err
+	}
+	defaults := map[string]string{}
+	for annotation, prop := range defaultPropertyAnnotations {
+		if value, ok := nsAnnotations[annotation]; ok {
+			defaults[prop] = value
+		}
+	}
+	return ksqlparser.SetDefaultProperties(stmts, defaults)
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// namingPolicyAnnotation on a Namespace overrides the naming policy of the ManagedKSQLs in it, an empty value turns
+// prefixing off
+const namingPolicyAnnotation = "mgazza.github.com/ksql-naming-policy"
+
+// namingData is what a naming policy template is executed with
+type namingData struct {
+	// Namespace is the namespace of the ManagedKSQL with - replaced by _ so that it's a valid identifier
+	Namespace string
+	// Name is the name of the stream, table or type, or the KAFKA_TOPIC of a stream or table created with a select, as
+	// written in the statement
+	Name string
+}
+
+// namingPolicySource is the naming policy for ManagedKSQLs in namespace as written, it's empty if names are used as
+// written
+func (c *Controller) namingPolicySource(namespace string) (string, error) {
+	annotations, err := c.namespaceAnnotations(namespace)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := annotations[namingPolicyAnnotation]; ok {
+		return p, nil
+	}
+	return c.defaultNamingPolicy, nil
+}
+
+// namingPolicy returns the naming policy template for ManagedKSQLs in namespace or nil if names are used as written
+func (c *Controller) namingPolicy(namespace string) (*template.Template, error) {
+	policy, err := c.namingPolicySource(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if policy == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("naming").Option("missingkey=error").Parse(policy)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing naming policy %q: %v", policy, err)
+	}
+	// catch references to fields which don't exist now rather than when renaming
+	if _, err := renderName(tmpl, namingData{}); err != nil {
+		return nil, fmt.Errorf("error executing naming policy %q: %v", policy, err)
+	}
+	return tmpl, nil
+}
+
+// namespaceAnnotations are the annotations of namespace, a namespace which doesn't exist has none
+func (c *Controller) namespaceAnnotations(namespace string) (map[string]string, error) {
+	ns, err := c.namespaceLister.Get(namespace)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting namespace %s: %v", namespace, err)
+	}
+	return ns.Annotations, nil
+}
+
+// enqueueNamespace enqueues every ManagedKSQL in the Namespace obj
+func (c *Controller) enqueueNamespace(obj interface{}) {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	managedKSQLs, err := c.managedKSQLLister.ManagedKSQLs(ns.Name).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing resources: %v", err)
+		return
+	}
+	klog.V(4).Infof("naming policy of namespace %s has changed", ns.Name)
+	for _, managedKSQL := range managedKSQLs {
+		c.enqueueManagedKSQL(managedKSQL)
+	}
+}
+
+func renderName(tmpl *template.Template, data namingData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// parseStatement parses the statement of managedKSQL, with its statementFrom and once its variables are substituted,
+// and applies the naming policy of its namespace.
+// It returns the real name of each stream and table declared keyed by the name written in the statement and the
+// streams properties of each statement keyed by its real name, SET and UNSET statements are left out.
+func (c *Controller) parseStatement(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) ([]ksqlparser.Stmt, map[string]string, map[string]map[string]string, error) {
+	statement, err := c.statement(managedKSQL)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if statement, err = c.substituteVariables(managedKSQL, statement); err != nil {
+		return nil, nil, nil, err
+	}
+	stmts, err := ksqlparser.Parse(statement)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	names, err := c.applyNamingPolicy(managedKSQL.Namespace, stmts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stmts, props := ksqlparser.StreamsProperties(stmts, managedKSQL.StreamsProperties)
+	return stmts, names, props, nil
+}
+
+// applyNamingPolicy renames the streams, tables and types declared by stmts, and the topics those created with a select
+// write to, with the naming policy of namespace.
+// It returns the real name of each keyed by the name written in the statement or nil if there's no policy.
+func (c *Controller) applyNamingPolicy(namespace string, stmts []ksqlparser.Stmt) (map[string]string, error) {
+	tmpl, err := c.namingPolicy(namespace)
+	if err != nil || tmpl == nil {
+		return nil, err
+	}
+
+	var declared []string
+	for _, stmt := range stmts {
+		if _, ok := stmt.(ksqlparser.CreateStmt); ok {
+			declared = append(declared, stmt.GetName())
+		}
+	}
+	namespace = strings.ReplaceAll(namespace, "-", "_")
+	rename := func(name string) string {
+		// the template has already been executed successfully so this can't fail
+		renamed, _ := renderName(tmpl, namingData{Namespace: namespace, Name: name})
+		return renamed
+	}
+	ksqlparser.Rename(stmts, rename)
+	// topics named explicitly would otherwise be shared by every namespace
+	ksqlparser.RenameTopics(stmts, rename)
+
+	names := map[string]string{}
+	i := 0
+	for _, stmt := range stmts {
+		if _, ok := stmt.(ksqlparser.CreateStmt); ok {
+			names[declared[i]] = stmt.GetName()
+			i++
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"ksql_operator/ksqlparser"
+)
+
+func TestApplyNamingPolicy(t *testing.T) {
+	namespaces := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "shared", Annotations: map[string]string{namingPolicyAnnotation: ""}}},
+	} {
+		if err := namespaces.Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := &Controller{
+		namespaceLister:     corelisters.NewNamespaceLister(namespaces),
+		defaultNamingPolicy: "{{.Namespace}}_{{.Name}}",
+	}
+	statement := `
+CREATE TYPE address AS STRUCT<city STRING>;
+CREATE STREAM orders (id STRING, delivery address) WITH (KAFKA_TOPIC='orders', VALUE_FORMAT='JSON');
+CREATE STREAM big WITH (KAFKA_TOPIC='big_orders') AS SELECT * FROM orders EMIT CHANGES;
+`
+	tests := []struct {
+		name      string
+		namespace string
+		wantNames map[string]string
+		want      []string
+	}{
+		{
+			name:      "default policy",
+			namespace: "team-a",
+			wantNames: map[string]string{"address": "team_a_address", "orders": "team_a_orders", "big": "team_a_big"},
+			want: []string{
+				"CREATE TYPE team_a_address AS STRUCT<city STRING>;",
+				"CREATE STREAM team_a_orders (id STRING, delivery team_a_address) WITH (KAFKA_TOPIC='orders', VALUE_FORMAT='JSON');",
+				"CREATE STREAM team_a_big WITH (KAFKA_TOPIC='team_a_big_orders') AS SELECT * FROM team_a_orders AS orders EMIT CHANGES;",
+			},
+		},
+		{
+			name:      "namespace without a policy",
+			namespace: "shared",
+			want: []string{
+				"CREATE TYPE address AS STRUCT<city STRING>;",
+				"CREATE STREAM orders (id STRING, delivery address) WITH (KAFKA_TOPIC='orders', VALUE_FORMAT='JSON');",
+				"CREATE STREAM big WITH (KAFKA_TOPIC='big_orders') AS SELECT * FROM orders EMIT CHANGES;",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := ksqlparser.Parse(statement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names, err := c.applyNamingPolicy(tt.namespace, stmts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(names, tt.wantNames); diff != nil {
+				t.Errorf("applyNamingPolicy() names: %v", diff)
+			}
+			for i, stmt := range stmts {
+				if got, want := ksqlparser.Normalise(stmt.String()), ksqlparser.Normalise(tt.want[i]); got != want {
+					t.Errorf("applyNamingPolicy() got = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -31,28 +32,302 @@ const (
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=mksql
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Applied",type=string,JSONPath=`.status.applied`
+// +kubebuilder:printcolumn:name="Statements",type=integer,JSONPath=`.status.statements`
+// +kubebuilder:printcolumn:name="Ready Queries",type=integer,JSONPath=`.status.readyQueries`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ManagedKSQL is a specification for a ManagedKSQL resource
 type ManagedKSQL struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Statement string            `json:"statement"`
-	Status    ManagedKSQLStatus `json:"status"`
+	// Statement is the ksql to apply, statements are separated by ;
+	// +optional
+	Statement string `json:"statement,omitempty"`
+	// StatementFrom are fragments of ksql applied after Statement, they're joined in order
+	// +optional
+	StatementFrom []StatementSource `json:"statementFrom,omitempty"`
+	// StreamsProperties are sent with each statement, SET and UNSET statements change them for the statements
+	// which follow
+	// +optional
+	StreamsProperties map[string]string `json:"streamsProperties,omitempty"`
+	// Variables are substituted for ${name} in the statement before it's parsed, DEFINE and UNDEFINE statements
+	// change them for the statements which follow
+	// +optional
+	Variables map[string]Variable `json:"variables,omitempty"`
+	// DryRun plans what applying the statement would do without doing it, the plan is written to status.plan
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// RequireApprovalFor are the kinds of action which wait for the plan to be approved before it's applied, a plan
+	// is approved by setting the mgazza.github.com/approved-plan annotation to its hash
+	// +optional
+	RequireApprovalFor []PlanActionType `json:"requireApprovalFor,omitempty"`
+	// UpgradeStrategy is how a stream or table is changed when ksqlDB won't replace it in place, Recreate by default
+	// +optional
+	UpgradeStrategy UpgradeStrategyType `json:"upgradeStrategy,omitempty"`
+	// CatchUpSeconds is how long the new version of a stream or table runs during a BlueGreen migration before the
+	// statements reading from it are moved over, 60 by default
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CatchUpSeconds *int32 `json:"catchUpSeconds,omitempty"`
+	// +optional
+	Status ManagedKSQLStatus `json:"status"`
+}
+
+// StatementSource is a fragment of ksql, only one of its fields may be set
+type StatementSource struct {
+	// +optional
+	Statement string `json:"statement,omitempty"`
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// Variable is the value of a variable, either Value or ValueFrom is set
+type Variable struct {
+	// +optional
+	Value string `json:"value,omitempty"`
+	// ValueFrom is a key of a ConfigMap or Secret in the namespace of the ManagedKSQL
+	// +optional
+	ValueFrom *VariableSource `json:"valueFrom,omitempty"`
+}
+
+// VariableSource is where the value of a variable is taken from, only one of its fields may be set
+type VariableSource struct {
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 // ManagedKSQLStatus is the status for a ManagedKSQL resource
 type ManagedKSQLStatus struct {
-	Applied    ResourceStatus           `json:"applied"`
+	// Applied is one of Applied, Pending or Failed
+	// +optional
+	Applied ResourceStatus `json:"applied"`
+	// ItemStatus is the status of each statement keyed by its name
+	// +optional
+	// +nullable
 	ItemStatus map[string]CommandStatus `json:"itemStatus"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Statements is the number of statements parsed
+	// +optional
+	Statements int `json:"statements,omitempty"`
+	// ReadyQueries is the number of persistent queries which are running
+	// +optional
+	ReadyQueries int `json:"readyQueries,omitempty"`
+	// Owned are the streams and tables this resource has claimed, no other ManagedKSQL may change them
+	Owned []string `json:"owned,omitempty"`
+	// Names are the names on the ksqlDB server of the streams and tables declared keyed by the names in the statement,
+	// they only differ when a naming policy applies or they've been migrated
+	Names map[string]string `json:"names,omitempty"`
+	// Plan is what applying the statement would do, it's only set for a dry run or while the plan is awaiting approval
+	// +optional
+	Plan *Plan `json:"plan,omitempty"`
+	// Migrations are the BlueGreen migrations in progress keyed by the name of the stream or table before it was
+	// versioned, a migration is removed once the old version has been dropped
+	// +optional
+	Migrations map[string]Migration `json:"migrations,omitempty"`
+	// Versions are the versions in use of the streams and tables which have been migrated keyed by their name before
+	// it was versioned
+	// +optional
+	Versions map[string]int `json:"versions,omitempty"`
 }
 
+// UpgradeStrategyType is how a stream or table is changed when ksqlDB won't replace it in place
+// +kubebuilder:validation:Enum=Recreate;BlueGreen
+type UpgradeStrategyType string
+
+const (
+	// UpgradeStrategyRecreate drops the stream or table, along with the queries reading from it, and creates it again
+	UpgradeStrategyRecreate = UpgradeStrategyType("Recreate")
+	// UpgradeStrategyBlueGreen creates a new version of a stream or table with a query alongside the old one, moves
+	// the statements reading from it over once it has caught up and then drops the old one
+	UpgradeStrategyBlueGreen = UpgradeStrategyType("BlueGreen")
+)
+
+// MigrationPhase is how far a BlueGreen migration has got
+type MigrationPhase string
+
+const (
+	// MigrationCatchingUp is a new version which has been created, the statements reading from the stream or table
+	// still read the old one
+	MigrationCatchingUp = MigrationPhase("CatchingUp")
+	// MigrationSwitching is a new version which has caught up, the statements reading from the stream or table are
+	// moved over to it and the old one is dropped
+	MigrationSwitching = MigrationPhase("Switching")
+)
+
+// Migration is the BlueGreen migration of a stream or table
+type Migration struct {
+	// Version is the version being migrated to, it's named <name>_V<version> and its topic, when the statement sets
+	// KAFKA_TOPIC, <topic>_v<version>
+	Version int `json:"version"`
+	// Name is the name of the version being migrated to
+	Name string `json:"name"`
+	// Previous is the name of the version being retired, it's empty once it has been dropped
+	// +optional
+	Previous string `json:"previous,omitempty"`
+	// Phase is CatchingUp or Switching
+	Phase MigrationPhase `json:"phase"`
+	// StartTime is when the migration started
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+}
+
+// PlanActionType is the kind of change a planned action makes
+// +kubebuilder:validation:Enum=Create;Replace;Insert;Drop;Terminate
+type PlanActionType string
+
+const (
+	PlanActionCreate    = PlanActionType("Create")
+	PlanActionReplace   = PlanActionType("Replace")
+	PlanActionInsert    = PlanActionType("Insert")
+	PlanActionDrop      = PlanActionType("Drop")
+	PlanActionTerminate = PlanActionType("Terminate")
+)
+
+// Plan is the ksql the controller would run to apply the statement
+type Plan struct {
+	// Hash identifies the actions, it changes whenever they do
+	Hash string `json:"hash"`
+	// ObservedGeneration is the generation of the resource which was planned
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Actions []PlannedAction `json:"actions,omitempty"`
+}
+
+// PlannedAction is a statement the controller would run
+type PlannedAction struct {
+	// Action is one of Create, Replace, Insert, Drop or Terminate
+	Action PlanActionType `json:"action"`
+	// Name is the statement the action is taken for
+	Name string `json:"name"`
+	// Statement is the ksql which would be run, the values of variables taken from a Secret are written as ${name}
+	Statement string `json:"statement"`
+	// Collateral is set when the action terminates a query which isn't run by the statement, such as one reading
+	// from a stream which is dropped
+	// +optional
+	Collateral bool `json:"collateral,omitempty"`
+	// MayRecreate is set on a Replace which ksqlDB may reject, the stream or table is then dropped along with the
+	// queries reading from it and created again
+	// +optional
+	MayRecreate bool `json:"mayRecreate,omitempty"`
+}
+
+const (
+	// ConditionParsed reports whether the statement could be parsed
+	ConditionParsed = "Parsed"
+	// ConditionDependenciesResolved reports whether the statements could be put in dependency order
+	ConditionDependenciesResolved = "DependenciesResolved"
+	// ConditionUpstreamReady reports whether the ManagedKSQLs declaring the sources read by this one are applied
+	ConditionUpstreamReady = "UpstreamReady"
+	// ConditionClaimed reports whether this resource owns every stream and table it declares
+	ConditionClaimed = "Claimed"
+	// ConditionPolicyCompliant reports whether the statements meet every KSQLPolicy applying to this resource
+	ConditionPolicyCompliant = "PolicyCompliant"
+	// ConditionAwaitingApproval reports whether the plan is waiting to be approved before it's applied
+	ConditionAwaitingApproval = "AwaitingApproval"
+)
+
+const (
+	ReasonParsed            = "Parsed"
+	ReasonParseError        = "ParseError"
+	ReasonVariableError     = "VariableError"
+	ReasonSourceError       = "SourceError"
+	ReasonResolved          = "Resolved"
+	ReasonDependencyError   = "DependencyError"
+	ReasonUpstreamReady     = "UpstreamReady"
+	ReasonWaitingOnUpstream = "WaitingOnUpstream"
+	ReasonClaimed           = "Claimed"
+	ReasonOwnershipConflict = "OwnershipConflict"
+	ReasonCompliant         = "Compliant"
+	ReasonPolicyViolation   = "PolicyViolation"
+	ReasonApprovalRequired  = "ApprovalRequired"
+	ReasonApproved          = "Approved"
+	ReasonNothingToApprove  = "NothingToApprove"
+	ReasonMigrationBlocked  = "MigrationBlocked"
+)
+
+// CommandStatus is the status of a statement on the ksqlDB server
 type CommandStatus struct {
+	// +optional
 	CommandID string `json:"commandID"`
-	QueryID   string `json:"queryID"`
-	Status    Status `json:"status"`
-	QuerySha  string `json:"querySha"`
+	// +optional
+	QueryID string `json:"queryID"`
+	// +optional
+	Status Status `json:"status"`
+	// +optional
+	QuerySha string `json:"querySha"`
+	// +optional
 	StatusSha string `json:"statusSha"`
+	// Connector is the state of the connector created by the statement
+	// +optional
+	Connector *ConnectorStatus `json:"connector,omitempty"`
+	// Upgrade is the outcome of the last change to the statement of a stream or table which already existed
+	// +optional
+	Upgrade *UpgradeStatus `json:"upgrade,omitempty"`
+}
+
+// UpgradeOutcome is how a stream or table was changed to match its statement
+type UpgradeOutcome string
+
+const (
+	// UpgradeReplaced is a stream or table changed in place with CREATE OR REPLACE
+	UpgradeReplaced = UpgradeOutcome("Replaced")
+	// UpgradeRejected is a change ksqlDB wouldn't make in place, the stream or table is dropped and created again
+	// when it's next synced
+	UpgradeRejected = UpgradeOutcome("Rejected")
+	// UpgradeRecreated is a stream or table dropped, along with the queries reading from it, and created again
+	UpgradeRecreated = UpgradeOutcome("Recreated")
+	// UpgradeMigrated is a stream or table migrated to a new version by a BlueGreen migration
+	UpgradeMigrated = UpgradeOutcome("Migrated")
+)
+
+// UpgradeStatus is the outcome of changing a stream or table to match its statement
+type UpgradeStatus struct {
+	Outcome UpgradeOutcome `json:"outcome"`
+	// QuerySha is the hash of the statement it was changed to
+	QuerySha string `json:"querySha"`
+	// Message is why ksqlDB rejected replacing it
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ConnectorStatus is the state of a connector and its tasks as described by ksqlDB
+type ConnectorStatus struct {
+	// Class is the class of the connector
+	// +optional
+	Class string `json:"class,omitempty"`
+	// State is RUNNING, PAUSED, FAILED or UNASSIGNED
+	// +optional
+	State string `json:"state,omitempty"`
+	// +optional
+	WorkerID string `json:"workerID,omitempty"`
+	// Trace is the error of a failed connector
+	// +optional
+	Trace string `json:"trace,omitempty"`
+	// +optional
+	Tasks []ConnectorTaskStatus `json:"tasks,omitempty"`
+}
+
+// ConnectorTaskStatus is the state of a task of a connector
+type ConnectorTaskStatus struct {
+	ID int `json:"id"`
+	// +optional
+	State string `json:"state,omitempty"`
+	// +optional
+	WorkerID string `json:"workerID,omitempty"`
+	// Trace is the error of a failed task
+	// +optional
+	Trace string `json:"trace,omitempty"`
 }
 
 type Status string
@@ -82,6 +357,7 @@ func ParseCommandStatus(status string) (Status, error) {
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
 
 // ManagedKSQLList is a list of ManagedKSQL resources
 type ManagedKSQLList struct {
@@ -90,3 +366,52 @@ type ManagedKSQLList struct {
 
 	Items []ManagedKSQL `json:"items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=kpol
+// +kubebuilder:printcolumn:name="Namespaces",type=string,JSONPath=`.spec.namespaces`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// KSQLPolicy restricts what the ManagedKSQLs in the namespaces it applies to may do
+type KSQLPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KSQLPolicySpec `json:"spec"`
+}
+
+// KSQLPolicySpec is the specification of a KSQLPolicy, every restriction which is set must be met
+type KSQLPolicySpec struct {
+	// Namespaces are the namespaces the policy applies to, it applies to every namespace when empty
+	Namespaces []string `json:"namespaces,omitempty"`
+	// AllowedSources are glob patterns of the streams and tables which may be read from, sources declared by the same
+	// ManagedKSQL may always be read
+	AllowedSources []string `json:"allowedSources,omitempty"`
+	// TopicPattern is a regular expression the whole of every Kafka topic must match
+	TopicPattern string `json:"topicPattern,omitempty"`
+	// MaxPartitions is the most PARTITIONS a stream or table may have
+	// +kubebuilder:validation:Minimum=1
+	MaxPartitions int `json:"maxPartitions,omitempty"`
+	// MaxReplicas is the most REPLICAS a stream or table may have
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int `json:"maxReplicas,omitempty"`
+	// ForbiddenFunctions are the functions which may not be called
+	ForbiddenFunctions []string `json:"forbiddenFunctions,omitempty"`
+	// AllowedValueFormats are the VALUE_FORMATs which may be used
+	AllowedValueFormats []string `json:"allowedValueFormats,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// KSQLPolicyList is a list of KSQLPolicy resources
+type KSQLPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []KSQLPolicy `json:"items"`
+}
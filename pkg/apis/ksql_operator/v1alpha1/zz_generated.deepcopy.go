@@ -21,12 +21,24 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommandStatus) DeepCopyInto(out *CommandStatus) {
 	*out = *in
+	if in.Connector != nil {
+		in, out := &in.Connector, &out.Connector
+		*out = new(ConnectorStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(UpgradeStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -40,11 +52,175 @@ func (in *CommandStatus) DeepCopy() *CommandStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectorStatus) DeepCopyInto(out *ConnectorStatus) {
+	*out = *in
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]ConnectorTaskStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectorStatus.
+func (in *ConnectorStatus) DeepCopy() *ConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectorTaskStatus) DeepCopyInto(out *ConnectorTaskStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectorTaskStatus.
+func (in *ConnectorTaskStatus) DeepCopy() *ConnectorTaskStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectorTaskStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KSQLPolicy) DeepCopyInto(out *KSQLPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KSQLPolicy.
+func (in *KSQLPolicy) DeepCopy() *KSQLPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(KSQLPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KSQLPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KSQLPolicyList) DeepCopyInto(out *KSQLPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KSQLPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KSQLPolicyList.
+func (in *KSQLPolicyList) DeepCopy() *KSQLPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(KSQLPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KSQLPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KSQLPolicySpec) DeepCopyInto(out *KSQLPolicySpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSources != nil {
+		in, out := &in.AllowedSources, &out.AllowedSources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenFunctions != nil {
+		in, out := &in.ForbiddenFunctions, &out.ForbiddenFunctions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedValueFormats != nil {
+		in, out := &in.AllowedValueFormats, &out.AllowedValueFormats
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KSQLPolicySpec.
+func (in *KSQLPolicySpec) DeepCopy() *KSQLPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KSQLPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagedKSQL) DeepCopyInto(out *ManagedKSQL) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.StatementFrom != nil {
+		in, out := &in.StatementFrom, &out.StatementFrom
+		*out = make([]StatementSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StreamsProperties != nil {
+		in, out := &in.StreamsProperties, &out.StreamsProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]Variable, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.RequireApprovalFor != nil {
+		in, out := &in.RequireApprovalFor, &out.RequireApprovalFor
+		*out = make([]PlanActionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.CatchUpSeconds != nil {
+		in, out := &in.CatchUpSeconds, &out.CatchUpSeconds
+		*out = new(int32)
+		**out = **in
+	}
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -106,6 +282,44 @@ func (in *ManagedKSQLStatus) DeepCopyInto(out *ManagedKSQLStatus) {
 	if in.ItemStatus != nil {
 		in, out := &in.ItemStatus, &out.ItemStatus
 		*out = make(map[string]CommandStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Owned != nil {
+		in, out := &in.Owned, &out.Owned
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(Plan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make(map[string]Migration, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make(map[string]int, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
@@ -122,3 +336,146 @@ func (in *ManagedKSQLStatus) DeepCopy() *ManagedKSQLStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Migration) DeepCopyInto(out *Migration) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Migration.
+func (in *Migration) DeepCopy() *Migration {
+	if in == nil {
+		return nil
+	}
+	out := new(Migration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Plan) DeepCopyInto(out *Plan) {
+	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]PlannedAction, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plan.
+func (in *Plan) DeepCopy() *Plan {
+	if in == nil {
+		return nil
+	}
+	out := new(Plan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedAction) DeepCopyInto(out *PlannedAction) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedAction.
+func (in *PlannedAction) DeepCopy() *PlannedAction {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatementSource) DeepCopyInto(out *StatementSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatementSource.
+func (in *StatementSource) DeepCopy() *StatementSource {
+	if in == nil {
+		return nil
+	}
+	out := new(StatementSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStatus.
+func (in *UpgradeStatus) DeepCopy() *UpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Variable) DeepCopyInto(out *Variable) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(VariableSource)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Variable.
+func (in *Variable) DeepCopy() *Variable {
+	if in == nil {
+		return nil
+	}
+	out := new(Variable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VariableSource) DeepCopyInto(out *VariableSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VariableSource.
+func (in *VariableSource) DeepCopy() *VariableSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VariableSource)
+	in.DeepCopyInto(out)
+	return out
+}
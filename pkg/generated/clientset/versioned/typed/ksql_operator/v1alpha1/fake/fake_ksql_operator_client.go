@@ -29,6 +29,10 @@ type FakeMgazzaV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeMgazzaV1alpha1) KSQLPolicies() v1alpha1.KSQLPolicyInterface {
+	return &FakeKSQLPolicies{c}
+}
+
 func (c *FakeMgazzaV1alpha1) ManagedKSQLs(namespace string) v1alpha1.ManagedKSQLInterface {
 	return &FakeManagedKSQLs{c, namespace}
 }
@@ -0,0 +1,122 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+	v1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeKSQLPolicies implements KSQLPolicyInterface
+type FakeKSQLPolicies struct {
+	Fake *FakeMgazzaV1alpha1
+}
+
+var ksqlpoliciesResource = schema.GroupVersionResource{Group: "mgazza.github.com", Version: "v1alpha1", Resource: "ksqlpolicies"}
+
+var ksqlpoliciesKind = schema.GroupVersionKind{Group: "mgazza.github.com", Version: "v1alpha1", Kind: "KSQLPolicy"}
+
+// Get takes name of the kSQLPolicy, and returns the corresponding kSQLPolicy object, and an error if there is any.
+func (c *FakeKSQLPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.KSQLPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(ksqlpoliciesResource, name), &v1alpha1.KSQLPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.KSQLPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of KSQLPolicies that match those selectors.
+func (c *FakeKSQLPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.KSQLPolicyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(ksqlpoliciesResource, ksqlpoliciesKind, opts), &v1alpha1.KSQLPolicyList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.KSQLPolicyList{ListMeta: obj.(*v1alpha1.KSQLPolicyList).ListMeta}
+	for _, item := range obj.(*v1alpha1.KSQLPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested kSQLPolicies.
+func (c *FakeKSQLPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(ksqlpoliciesResource, opts))
+}
+
+// Create takes the representation of a kSQLPolicy and creates it.  Returns the server's representation of the kSQLPolicy, and an error, if there is any.
+func (c *FakeKSQLPolicies) Create(ctx context.Context, kSQLPolicy *v1alpha1.KSQLPolicy, opts v1.CreateOptions) (result *v1alpha1.KSQLPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(ksqlpoliciesResource, kSQLPolicy), &v1alpha1.KSQLPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.KSQLPolicy), err
+}
+
+// Update takes the representation of a kSQLPolicy and updates it. Returns the server's representation of the kSQLPolicy, and an error, if there is any.
+func (c *FakeKSQLPolicies) Update(ctx context.Context, kSQLPolicy *v1alpha1.KSQLPolicy, opts v1.UpdateOptions) (result *v1alpha1.KSQLPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(ksqlpoliciesResource, kSQLPolicy), &v1alpha1.KSQLPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.KSQLPolicy), err
+}
+
+// Delete takes name of the kSQLPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeKSQLPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(ksqlpoliciesResource, name), &v1alpha1.KSQLPolicy{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeKSQLPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(ksqlpoliciesResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.KSQLPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched kSQLPolicy.
+func (c *FakeKSQLPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.KSQLPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(ksqlpoliciesResource, name, pt, data, subresources...), &v1alpha1.KSQLPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.KSQLPolicy), err
+}
@@ -27,6 +27,7 @@ import (
 
 type MgazzaV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	KSQLPoliciesGetter
 	ManagedKSQLsGetter
 }
 
@@ -35,6 +36,10 @@ type MgazzaV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *MgazzaV1alpha1Client) KSQLPolicies() KSQLPolicyInterface {
+	return newKSQLPolicies(c)
+}
+
 func (c *MgazzaV1alpha1Client) ManagedKSQLs(namespace string) ManagedKSQLInterface {
 	return newManagedKSQLs(c, namespace)
 }
@@ -0,0 +1,168 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	v1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+	scheme "ksql_operator/pkg/generated/clientset/versioned/scheme"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// KSQLPoliciesGetter has a method to return a KSQLPolicyInterface.
+// A group's client should implement this interface.
+type KSQLPoliciesGetter interface {
+	KSQLPolicies() KSQLPolicyInterface
+}
+
+// KSQLPolicyInterface has methods to work with KSQLPolicy resources.
+type KSQLPolicyInterface interface {
+	Create(ctx context.Context, kSQLPolicy *v1alpha1.KSQLPolicy, opts v1.CreateOptions) (*v1alpha1.KSQLPolicy, error)
+	Update(ctx context.Context, kSQLPolicy *v1alpha1.KSQLPolicy, opts v1.UpdateOptions) (*v1alpha1.KSQLPolicy, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.KSQLPolicy, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.KSQLPolicyList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.KSQLPolicy, err error)
+	KSQLPolicyExpansion
+}
+
+// kSQLPolicies implements KSQLPolicyInterface
+type kSQLPolicies struct {
+	client rest.Interface
+}
+
+// newKSQLPolicies returns a KSQLPolicies
+func newKSQLPolicies(c *MgazzaV1alpha1Client) *kSQLPolicies {
+	return &kSQLPolicies{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the kSQLPolicy, and returns the corresponding kSQLPolicy object, and an error if there is any.
+func (c *kSQLPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.KSQLPolicy, err error) {
+	result = &v1alpha1.KSQLPolicy{}
+	err = c.client.Get().
+		Resource("ksqlpolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of KSQLPolicies that match those selectors.
+func (c *kSQLPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.KSQLPolicyList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.KSQLPolicyList{}
+	err = c.client.Get().
+		Resource("ksqlpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested kSQLPolicies.
+func (c *kSQLPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("ksqlpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a kSQLPolicy and creates it.  Returns the server's representation of the kSQLPolicy, and an error, if there is any.
+func (c *kSQLPolicies) Create(ctx context.Context, kSQLPolicy *v1alpha1.KSQLPolicy, opts v1.CreateOptions) (result *v1alpha1.KSQLPolicy, err error) {
+	result = &v1alpha1.KSQLPolicy{}
+	err = c.client.Post().
+		Resource("ksqlpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(kSQLPolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a kSQLPolicy and updates it. Returns the server's representation of the kSQLPolicy, and an error, if there is any.
+func (c *kSQLPolicies) Update(ctx context.Context, kSQLPolicy *v1alpha1.KSQLPolicy, opts v1.UpdateOptions) (result *v1alpha1.KSQLPolicy, err error) {
+	result = &v1alpha1.KSQLPolicy{}
+	err = c.client.Put().
+		Resource("ksqlpolicies").
+		Name(kSQLPolicy.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(kSQLPolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the kSQLPolicy and deletes it. Returns an error if one occurs.
+func (c *kSQLPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("ksqlpolicies").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *kSQLPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("ksqlpolicies").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched kSQLPolicy.
+func (c *kSQLPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.KSQLPolicy, err error) {
+	result = &v1alpha1.KSQLPolicy{}
+	err = c.client.Patch(pt).
+		Resource("ksqlpolicies").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
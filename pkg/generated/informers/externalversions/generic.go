@@ -53,6 +53,8 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=mgazza.github.com, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("ksqlpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Mgazza().V1alpha1().KSQLPolicies().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("managedksqls"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Mgazza().V1alpha1().ManagedKSQLs().Informer()}, nil
 
@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// KSQLPolicies returns a KSQLPolicyInformer.
+	KSQLPolicies() KSQLPolicyInformer
 	// ManagedKSQLs returns a ManagedKSQLInformer.
 	ManagedKSQLs() ManagedKSQLInformer
 }
@@ -39,6 +41,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// KSQLPolicies returns a KSQLPolicyInformer.
+func (v *version) KSQLPolicies() KSQLPolicyInformer {
+	return &kSQLPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // ManagedKSQLs returns a ManagedKSQLInformer.
 func (v *version) ManagedKSQLs() ManagedKSQLInformer {
 	return &managedKSQLInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
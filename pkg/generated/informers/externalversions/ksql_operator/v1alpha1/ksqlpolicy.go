@@ -0,0 +1,89 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+	versioned "ksql_operator/pkg/generated/clientset/versioned"
+	internalinterfaces "ksql_operator/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "ksql_operator/pkg/generated/listers/ksql_operator/v1alpha1"
+	time "time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// KSQLPolicyInformer provides access to a shared informer and lister for
+// KSQLPolicies.
+type KSQLPolicyInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.KSQLPolicyLister
+}
+
+type kSQLPolicyInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewKSQLPolicyInformer constructs a new informer for KSQLPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewKSQLPolicyInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredKSQLPolicyInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredKSQLPolicyInformer constructs a new informer for KSQLPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredKSQLPolicyInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.MgazzaV1alpha1().KSQLPolicies().List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.MgazzaV1alpha1().KSQLPolicies().Watch(context.TODO(), options)
+			},
+		},
+		&ksqloperatorv1alpha1.KSQLPolicy{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *kSQLPolicyInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredKSQLPolicyInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *kSQLPolicyInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&ksqloperatorv1alpha1.KSQLPolicy{}, f.defaultInformer)
+}
+
+func (f *kSQLPolicyInformer) Lister() v1alpha1.KSQLPolicyLister {
+	return v1alpha1.NewKSQLPolicyLister(f.Informer().GetIndexer())
+}
@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1alpha1
 
+// KSQLPolicyListerExpansion allows custom methods to be added to
+// KSQLPolicyLister.
+type KSQLPolicyListerExpansion interface{}
+
 // ManagedKSQLListerExpansion allows custom methods to be added to
 // ManagedKSQLLister.
 type ManagedKSQLListerExpansion interface{}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ksql_operator/ksqlclient/swagger"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// dryRunID prefixes the command and query ids given to the statements recorded by a dryRunClient
+const dryRunID = "dry-run-"
+
+// readOnlyKeywords start the statements a dryRunClient passes through to the ksql server
+var readOnlyKeywords = []string{"DESCRIBE", "EXPLAIN", "LIST", "SHOW"}
+
+// dryRunClient passes the statements which only read from the ksql server through and records the rest as the
+// actions of a plan, answering them as though they succeeded
+type dryRunClient struct {
+	KSQLClient
+	// name is the statement being planned
+	name string
+	// queries are the ids of the queries run by the resource being planned
+	queries map[string]bool
+	actions []ksqloperatorv1alpha1.PlannedAction
+	// statements are the statements recorded keyed by the id they were given
+	statements map[string]string
+	// redact replaces the values of the variables taken from Secrets in the statements of the plan
+	redact *strings.Replacer
+	// recreates is true when a stream or table ksqlDB won't replace is dropped and created again
+	recreates bool
+}
+
+// newDryRunClient records the statements of the resource with status, the values of secrets are written in the plan
+// as references to the variables keyed by their name
+func newDryRunClient(client KSQLClient, status ksqloperatorv1alpha1.ManagedKSQLStatus, secrets map[string]string) *dryRunClient {
+	queries := map[string]bool{}
+	for _, commandStatus := range status.ItemStatus {
+		if commandStatus.QueryID != "" {
+			queries[commandStatus.QueryID] = true
+		}
+	}
+	var names []string
+	for name, value := range secrets {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	// the longest values first so that one containing another is replaced whole
+	sort.Slice(names, func(a, b int) bool {
+		if len(secrets[names[a]]) != len(secrets[names[b]]) {
+			return len(secrets[names[a]]) > len(secrets[names[b]])
+		}
+		return names[a] < names[b]
+	})
+	var oldnew []string
+	for _, name := range names {
+		oldnew = append(oldnew, secrets[name], "${"+name+"}")
+	}
+	return &dryRunClient{KSQLClient: client, queries: queries, statements: map[string]string{},
+		redact: strings.NewReplacer(oldnew...)}
+}
+
+// record adds ksql to the plan and returns the id it's given
+func (d *dryRunClient) record(ksql string) string {
+	action := ksqloperatorv1alpha1.PlannedAction{Name: d.name, Statement: d.redact.Replace(ksql)}
+	fields := strings.Fields(strings.TrimSuffix(ksql, ";"))
+	switch upper := strings.ToUpper(ksql); {
+	case strings.HasPrefix(upper, "TERMINATE"):
+		action.Action = ksqloperatorv1alpha1.PlanActionTerminate
+		action.Collateral = len(fields) > 1 && !d.queries[fields[1]]
+	case strings.HasPrefix(upper, "DROP"):
+		action.Action = ksqloperatorv1alpha1.PlanActionDrop
+	case strings.HasPrefix(upper, ksqlparser.ReservedCreateOrReplace):
+		action.Action = ksqloperatorv1alpha1.PlanActionReplace
+		// whether ksqlDB accepts the change is only known once it's run
+		action.MayRecreate = d.recreates
+	case strings.HasPrefix(upper, "INSERT"):
+		action.Action = ksqloperatorv1alpha1.PlanActionInsert
+	default:
+		action.Action = ksqloperatorv1alpha1.PlanActionCreate
+	}
+	d.actions = append(d.actions, action)
+	id := fmt.Sprintf("%s%d", dryRunID, len(d.actions))
+	d.statements[id] = ksql
+	return id
+}
+
+func (d *dryRunClient) succeeded(ksql string) *[]swagger.CreateDropTerminateResponseItem {
+	id := d.record(ksql)
+	return &[]swagger.CreateDropTerminateResponseItem{{
+		StatementText: ksql,
+		CommandId:     id,
+		CommandStatus: &swagger.CreateDropTerminateResponseCommandStatus{
+			Status:  string(ksqloperatorv1alpha1.StatusSuccess),
+			QueryId: id,
+		},
+	}}
+}
+
+func (d *dryRunClient) CreateDropTerminate(ctx context.Context, ksql string) (interface{}, error) {
+	return d.succeeded(ksql), nil
+}
+
+func (d *dryRunClient) Execute(ctx context.Context, ksql string, result interface{}) (interface{}, error) {
+	if fields := strings.Fields(ksql); len(fields) > 0 {
+		for _, keyword := range readOnlyKeywords {
+			if strings.EqualFold(fields[0], keyword) {
+				return d.KSQLClient.Execute(ctx, ksql, result)
+			}
+		}
+	}
+	switch result := result.(type) {
+	case *[]swagger.ConnectorResponseItem:
+		d.record(ksql)
+		*result = append(*result, swagger.ConnectorResponseItem{StatementText: ksql})
+		return result, nil
+	case *[]swagger.CreateDropTerminateResponseItem:
+		*result = append(*result, (*d.succeeded(ksql))...)
+		return result, nil
+	}
+	d.record(ksql)
+	return result, nil
+}
+
+func (d *dryRunClient) Status(ctx context.Context, commandID string) (interface{}, error) {
+	if _, ok := d.statements[commandID]; ok {
+		return &swagger.StatusResponse{Status: string(ksqloperatorv1alpha1.StatusSuccess)}, nil
+	}
+	return d.KSQLClient.Status(ctx, commandID)
+}
+
+func (d *dryRunClient) Explain(ctx context.Context, name string) (interface{}, error) {
+	if ksql, ok := d.statements[name]; ok {
+		return &[]swagger.ExplainResultItem{{
+			QueryDescription: &swagger.ExplainResultItemQueryDescription{
+				Id:            name,
+				StatementText: ksql,
+				State:         string(ksqloperatorv1alpha1.StatusSuccess),
+			},
+		}}, nil
+	}
+	return d.KSQLClient.Explain(ctx, name)
+}
+
+// plan returns what applying stmts to managedKSQL would do. The statements are applied as usual, with a copy of
+// its status, by a controller whose ksql client only reads from the server so the plan makes the same decisions.
+func (c *Controller) plan(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, stmts []ksqlparser.Stmt, props map[string]map[string]string) (*ksqloperatorv1alpha1.Plan, error) {
+	secrets, err := c.secretVariables(managedKSQL)
+	if err != nil {
+		return nil, err
+	}
+	cp := managedKSQL.DeepCopy()
+	recorder := newDryRunClient(c.ksqlClient, cp.Status, secrets)
+	// a migration keeps the old version, it's planned again as the readers are moved over
+	recorder.recreates = managedKSQL.UpgradeStrategy != ksqloperatorv1alpha1.UpgradeStrategyBlueGreen
+	planner := *c
+	planner.ksqlClient = recorder
+	// nothing more is applied once a migration starts
+	if err := planner.apply(cp, stmts, props); err != nil && err != errMigrationStarted {
+		return nil, fmt.Errorf("error planning: %v", err)
+	}
+
+	actions, err := json.Marshal(recorder.actions)
+	if err != nil {
+		return nil, err
+	}
+	return &ksqloperatorv1alpha1.Plan{
+		Hash:               DefaultHasher(string(actions)),
+		ObservedGeneration: managedKSQL.Generation,
+		Actions:            recorder.actions,
+	}, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+	"ksql_operator/ksqlclient"
+	"ksql_operator/ksqlclient/swagger"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// fakeKSQLClient records the statements it's sent, describes the sources in sources and explains the queries in
+// states
+type fakeKSQLClient struct {
+	sent []string
+	// sources are the descriptions of the streams and tables keyed by their name
+	sources map[string]swagger.DescribeResultItemSourceDescription
+	// states are the states of the queries explained keyed by their id
+	states map[string]string
+}
+
+func (f *fakeKSQLClient) Describe(ctx context.Context, name string) (interface{}, error) {
+	f.sent = append(f.sent, "DESCRIBE "+name)
+	source, ok := f.sources[name]
+	if !ok {
+		return &swagger.ModelError{ErrorCode: ksqlclient.ErrCodeNotFound, Message: name + " not found"}, nil
+	}
+	return &[]swagger.DescribeResultItem{{SourceDescription: &source}}, nil
+}
+
+func (f *fakeKSQLClient) Explain(ctx context.Context, name string) (interface{}, error) {
+	f.sent = append(f.sent, "EXPLAIN "+name)
+	state, ok := f.states[name]
+	if !ok {
+		return &swagger.ModelError{Message: name + " not found"}, nil
+	}
+	return &[]swagger.ExplainResultItem{{
+		QueryDescription: &swagger.ExplainResultItemQueryDescription{Id: name, State: state},
+	}}, nil
+}
+
+func (f *fakeKSQLClient) Execute(ctx context.Context, stmt string, result interface{}) (interface{}, error) {
+	f.sent = append(f.sent, stmt)
+	return result, nil
+}
+
+func (f *fakeKSQLClient) CreateDropTerminate(ctx context.Context, stmt string) (interface{}, error) {
+	f.sent = append(f.sent, stmt)
+	return &[]swagger.CreateDropTerminateResponseItem{}, nil
+}
+
+func (f *fakeKSQLClient) Status(tx context.Context, commandID string) (interface{}, error) {
+	f.sent = append(f.sent, "STATUS "+commandID)
+	return &swagger.StatusResponse{}, nil
+}
+
+func TestDryRunClient(t *testing.T) {
+	server := &fakeKSQLClient{}
+	d := newDryRunClient(server, ksqloperatorv1alpha1.ManagedKSQLStatus{
+		ItemStatus: map[string]ksqloperatorv1alpha1.CommandStatus{"A": {QueryID: "CSAS_A_1"}},
+	}, map[string]string{"token": "s3cr3t", "prefix": "s3", "empty": ""})
+	ctx := context.Background()
+	d.name = "A"
+	d.recreates = true
+	for _, ksql := range []string{
+		"CREATE STREAM a AS SELECT * FROM b EMIT CHANGES;",
+		"CREATE OR REPLACE STREAM a AS SELECT * FROM b EMIT CHANGES;",
+		"INSERT INTO a SELECT * FROM c WHERE token = 's3cr3t' OR id = 's3';",
+		"TERMINATE CSAS_A_1;",
+		"TERMINATE CSAS_OTHER_2;",
+		"DROP STREAM a;",
+	} {
+		if _, err := d.CreateDropTerminate(ctx, ksql); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var items []swagger.CreateDropTerminateResponseItem
+	if _, err := d.Execute(ctx, "SHOW QUERIES;", &items); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Execute(ctx, "DROP CONNECTOR c;", &items); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ksqloperatorv1alpha1.PlannedAction{
+		{Action: ksqloperatorv1alpha1.PlanActionCreate, Name: "A", Statement: "CREATE STREAM a AS SELECT * FROM b EMIT CHANGES;"},
+		{Action: ksqloperatorv1alpha1.PlanActionReplace, Name: "A", Statement: "CREATE OR REPLACE STREAM a AS SELECT * FROM b EMIT CHANGES;", MayRecreate: true},
+		{Action: ksqloperatorv1alpha1.PlanActionInsert, Name: "A", Statement: "INSERT INTO a SELECT * FROM c WHERE token = '${token}' OR id = '${prefix}';"},
+		{Action: ksqloperatorv1alpha1.PlanActionTerminate, Name: "A", Statement: "TERMINATE CSAS_A_1;"},
+		{Action: ksqloperatorv1alpha1.PlanActionTerminate, Name: "A", Statement: "TERMINATE CSAS_OTHER_2;", Collateral: true},
+		{Action: ksqloperatorv1alpha1.PlanActionDrop, Name: "A", Statement: "DROP STREAM a;"},
+		{Action: ksqloperatorv1alpha1.PlanActionDrop, Name: "A", Statement: "DROP CONNECTOR c;"},
+	}
+	if diff := deep.Equal(d.actions, want); diff != nil {
+		t.Errorf("actions got = %v, want %v: %v", d.actions, want, diff)
+	}
+	if diff := deep.Equal(server.sent, []string{"SHOW QUERIES;"}); diff != nil {
+		t.Errorf("sent to the server got = %v: %v", server.sent, diff)
+	}
+
+	status, err := d.Status(ctx, dryRunID+"1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := status.(*swagger.StatusResponse).Status; got != string(ksqloperatorv1alpha1.StatusSuccess) {
+		t.Errorf("Status() of a recorded statement got = %s", got)
+	}
+	explained, err := d.Explain(ctx, dryRunID+"1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (*explained.(*[]swagger.ExplainResultItem))[0].QueryDescription.StatementText; got != want[0].Statement {
+		t.Errorf("Explain() of a recorded statement got = %s", got)
+	}
+	if _, err := d.Explain(ctx, "CSAS_A_1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.sent[len(server.sent)-1]; got != "EXPLAIN CSAS_A_1" {
+		t.Errorf("Explain() of a running query wasn't passed through, got = %s", got)
+	}
+}
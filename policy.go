@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// policyApplies reports whether policy restricts the ManagedKSQLs in namespace
+func policyApplies(policy *ksqloperatorv1alpha1.KSQLPolicy, namespace string) bool {
+	if len(policy.Spec.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range policy.Spec.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// policyViolations returns a message for each way stmts of a ManagedKSQL in namespace break the policies applying
+// to it. Names are checked as they will be on the ksqlDB server, after any naming policy.
+func policyViolations(policies []*ksqloperatorv1alpha1.KSQLPolicy, namespace string, stmts []ksqlparser.Stmt) []string {
+	declared := map[string]bool{}
+	for _, stmt := range stmts {
+		if _, ok := stmt.(ksqlparser.CreateStmt); ok {
+			declared[strings.ToUpper(stmt.GetName())] = true
+		}
+	}
+
+	var msgs []string
+	for _, policy := range policies {
+		if !policyApplies(policy, namespace) {
+			continue
+		}
+		violation := func(format string, args ...interface{}) {
+			msgs = append(msgs, fmt.Sprintf("policy %s: %s", policy.Name, fmt.Sprintf(format, args...)))
+		}
+		spec := policy.Spec
+
+		var topicPattern *regexp.Regexp
+		if spec.TopicPattern != "" {
+			var err error
+			if topicPattern, err = regexp.Compile("^(?:" + spec.TopicPattern + ")$"); err != nil {
+				violation("invalid topicPattern %q: %v", spec.TopicPattern, err)
+			}
+		}
+
+		for _, stmt := range stmts {
+			name := stmtDescription(stmt)
+
+			if len(spec.AllowedSources) > 0 {
+				types := ksqlparser.Types(stmt)
+				for _, source := range ksqlparser.Dependencies(stmt) {
+					if contains(types, source) {
+						// types aren't sources
+						continue
+					}
+					if !declared[strings.ToUpper(source)] && !matchesAny(spec.AllowedSources, source) {
+						violation("%s uses %s which isn't an allowed source", name, source)
+					}
+				}
+			}
+
+			for _, function := range ksqlparser.Functions(stmt) {
+				for _, forbidden := range spec.ForbiddenFunctions {
+					if strings.EqualFold(function, forbidden) {
+						violation("%s calls forbidden function %s", name, function)
+					}
+				}
+			}
+
+			if connectorType, ok := ksqlparser.ConnectorTypeOf(stmt); ok {
+				for _, msg := range connectorViolations(spec, topicPattern, connectorType, ksqlparser.Properties(stmt)) {
+					violation("%s %s", name, msg)
+				}
+				continue
+			}
+
+			if createStmt, ok := stmt.(ksqlparser.CreateStmt); !ok || (createStmt.GetObjectType() != ksqlparser.CreateObjectTypeStream &&
+				createStmt.GetObjectType() != ksqlparser.CreateObjectTypeTable) {
+				continue
+			}
+			props := ksqlparser.Properties(stmt)
+
+			topic := strings.ToUpper(stmt.GetName())
+			if t, ok := props[ksqlparser.WithPropertyKafkaTopic]; ok {
+				topic = strings.Trim(t, "'")
+			}
+			if topicPattern != nil && !topicPattern.MatchString(topic) {
+				violation("%s uses topic %s which doesn't match %s", name, topic, spec.TopicPattern)
+			}
+
+			if partitions, _ := strconv.Atoi(props[ksqlparser.WithPropertyPartitions]); spec.MaxPartitions > 0 && partitions > spec.MaxPartitions {
+				violation("%s has %d PARTITIONS, at most %d are allowed", name, partitions, spec.MaxPartitions)
+			}
+			if replicas, _ := strconv.Atoi(props[ksqlparser.WithPropertyReplicas]); spec.MaxReplicas > 0 && replicas > spec.MaxReplicas {
+				violation("%s has %d REPLICAS, at most %d are allowed", name, replicas, spec.MaxReplicas)
+			}
+
+			if format, ok := props[ksqlparser.WithPropertyValueFormat]; ok && len(spec.AllowedValueFormats) > 0 {
+				format = strings.Trim(format, "'")
+				allowed := false
+				for _, f := range spec.AllowedValueFormats {
+					allowed = allowed || strings.EqualFold(f, format)
+				}
+				if !allowed {
+					violation("%s uses VALUE_FORMAT %s which isn't one of %s", name, format,
+						strings.Join(spec.AllowedValueFormats, ", "))
+				}
+			}
+		}
+	}
+	return msgs
+}
+
+// connectorTopicProperties are the connector properties which list the topics a connector reads or writes
+var connectorTopicProperties = []string{"topics", "topic", "kafka.topic"}
+
+// connectorTopicPatternProperties are the connector properties which name topics by a pattern or prefix, they can't
+// be checked against a topicPattern
+var connectorTopicPatternProperties = []string{"topics.regex", "topic.prefix"}
+
+// converterFormats are the VALUE_FORMATs written by Kafka Connect converters keyed by the simple name of their class
+var converterFormats = map[string]string{
+	"AvroConverter":       "AVRO",
+	"JsonConverter":       "JSON",
+	"JsonSchemaConverter": "JSON_SR",
+	"ProtobufConverter":   "PROTOBUF",
+	"StringConverter":     "KAFKA",
+}
+
+// connectorViolations returns a message for each way a connector of connectorType with props breaks spec.
+// Connectors name their topics and formats with properties particular to each, those which can't be checked break
+// the restrictions they could get around.
+func connectorViolations(spec ksqloperatorv1alpha1.KSQLPolicySpec, topicPattern *regexp.Regexp,
+	connectorType ksqlparser.ConnectorType, props map[string]string) []string {
+	var msgs []string
+	value := func(prop string) (string, bool) {
+		v, ok := props[prop]
+		return strings.Trim(v, "'"), ok
+	}
+
+	if topicPattern != nil {
+		named := false
+		for _, prop := range connectorTopicProperties {
+			topics, ok := value(prop)
+			if !ok {
+				continue
+			}
+			named = true
+			for _, topic := range strings.Split(topics, ",") {
+				if topic = strings.TrimSpace(topic); !topicPattern.MatchString(topic) {
+					msgs = append(msgs, fmt.Sprintf("uses topic %s which doesn't match %s", topic, spec.TopicPattern))
+				}
+			}
+		}
+		for _, prop := range connectorTopicPatternProperties {
+			if _, ok := value(prop); ok {
+				named = true
+				msgs = append(msgs, fmt.Sprintf("names its topics by %s which can't be checked against %s", prop, spec.TopicPattern))
+			}
+		}
+		if !named {
+			msgs = append(msgs, fmt.Sprintf("doesn't list its topics in %s so they can't be checked against %s",
+				strings.Join(connectorTopicProperties, ", "), spec.TopicPattern))
+		}
+	}
+
+	partitions, _ := value("topic.creation.default.partitions")
+	if partitions, _ := strconv.Atoi(partitions); spec.MaxPartitions > 0 && partitions > spec.MaxPartitions {
+		msgs = append(msgs, fmt.Sprintf("creates topics with %d partitions, at most %d are allowed", partitions, spec.MaxPartitions))
+	}
+	replicas, _ := value("topic.creation.default.replication.factor")
+	if replicas, _ := strconv.Atoi(replicas); spec.MaxReplicas > 0 && replicas > spec.MaxReplicas {
+		msgs = append(msgs, fmt.Sprintf("creates topics with %d replicas, at most %d are allowed", replicas, spec.MaxReplicas))
+	}
+
+	// only what a source connector writes has to be in an allowed format
+	if len(spec.AllowedValueFormats) == 0 || connectorType != ksqlparser.ConnectorTypeSource {
+		return msgs
+	}
+	converter, ok := value("value.converter")
+	if !ok {
+		return append(msgs, "doesn't set value.converter so its VALUE_FORMAT can't be checked")
+	}
+	format, ok := converterFormats[converter[strings.LastIndex(converter, ".")+1:]]
+	if !ok {
+		return append(msgs, fmt.Sprintf("uses value.converter %s which doesn't write one of %s", converter,
+			strings.Join(spec.AllowedValueFormats, ", ")))
+	}
+	allowed := false
+	for _, f := range spec.AllowedValueFormats {
+		allowed = allowed || strings.EqualFold(f, format)
+	}
+	if !allowed {
+		msgs = append(msgs, fmt.Sprintf("uses VALUE_FORMAT %s which isn't one of %s", format,
+			strings.Join(spec.AllowedValueFormats, ", ")))
+	}
+	return msgs
+}
+
+// stmtDescription names stmt in messages, inserts are named by a hash so they are described by their target
+func stmtDescription(stmt ksqlparser.Stmt) string {
+	if t := stmt.GetActionType(); t != ksqlparser.StmtTypeInsert && t != ksqlparser.StmtTypeInsertValues {
+		return stmt.GetName()
+	}
+	// the target of an insert is its last dependency
+	deps := ksqlparser.Dependencies(stmt)
+	return fmt.Sprintf("INSERT INTO %s", deps[len(deps)-1])
+}
+
+// matchesAny reports whether name matches any of the glob patterns ignoring case
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(pattern), strings.ToUpper(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueAll puts every ManagedKSQL onto the work queue
+func (c *Controller) enqueueAll() {
+	managedKSQLs, err := c.managedKSQLLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing resources: %v", err)
+		return
+	}
+	for _, managedKSQL := range managedKSQLs {
+		c.enqueueManagedKSQL(managedKSQL)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+func TestPolicyViolations(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      ksqloperatorv1alpha1.KSQLPolicySpec
+		namespace string
+		sql       string
+		want      []string
+	}{
+		{
+			name: "allowed sources",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{AllowedSources: []string{"RAW_*"}},
+			sql: `
+CREATE STREAM a AS SELECT * FROM raw_events EMIT CHANGES;
+CREATE STREAM b AS SELECT * FROM a EMIT CHANGES;
+CREATE STREAM c AS SELECT * FROM other EMIT CHANGES;`,
+			want: []string{"policy test: c uses other which isn't an allowed source"},
+		},
+		{
+			name: "forbidden functions",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{ForbiddenFunctions: []string{"ucase"}},
+			sql:  "CREATE STREAM a AS SELECT UCASE(id) AS id FROM b EMIT CHANGES;",
+			want: []string{"policy test: a calls forbidden function UCASE"},
+		},
+		{
+			name: "topic pattern",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{TopicPattern: "team-.*"},
+			sql: `
+CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='team-a', VALUE_FORMAT='JSON');
+CREATE STREAM b (id STRING) WITH (KAFKA_TOPIC='other-b', VALUE_FORMAT='JSON');
+CREATE STREAM c AS SELECT * FROM a EMIT CHANGES;`,
+			want: []string{
+				"policy test: b uses topic other-b which doesn't match team-.*",
+				"policy test: c uses topic C which doesn't match team-.*",
+			},
+		},
+		{
+			name: "invalid topic pattern",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{TopicPattern: "("},
+			sql:  "CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON');",
+			want: []string{"policy test: invalid topicPattern \"(\": error parsing regexp: missing closing ): `^(?:()$`"},
+		},
+		{
+			name: "partitions and replicas",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{MaxPartitions: 6, MaxReplicas: 3},
+			sql:  "CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON', PARTITIONS=12, REPLICAS=3);",
+			want: []string{"policy test: a has 12 PARTITIONS, at most 6 are allowed"},
+		},
+		{
+			name: "value formats",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{AllowedValueFormats: []string{"AVRO", "PROTOBUF"}},
+			sql: `
+CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='avro');
+CREATE STREAM b (id STRING) WITH (KAFKA_TOPIC='b', VALUE_FORMAT='JSON');`,
+			want: []string{"policy test: b uses VALUE_FORMAT JSON which isn't one of AVRO, PROTOBUF"},
+		},
+		{
+			name: "insert",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{AllowedSources: []string{"a"}},
+			sql:  "INSERT INTO a SELECT * FROM b;",
+			want: []string{"policy test: INSERT INTO a uses b which isn't an allowed source"},
+		},
+		{
+			name:      "other namespace",
+			spec:      ksqloperatorv1alpha1.KSQLPolicySpec{Namespaces: []string{"team"}, MaxPartitions: 1},
+			namespace: "other",
+			sql:       "CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON', PARTITIONS=12);",
+		},
+		{
+			name:      "namespace",
+			spec:      ksqloperatorv1alpha1.KSQLPolicySpec{Namespaces: []string{"team"}, MaxPartitions: 1},
+			namespace: "team",
+			sql:       "CREATE STREAM a (id STRING) WITH (KAFKA_TOPIC='a', VALUE_FORMAT='JSON', PARTITIONS=12);",
+			want:      []string{"policy test: a has 12 PARTITIONS, at most 1 are allowed"},
+		},
+		{
+			name: "connector topics",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{TopicPattern: "team-.*"},
+			sql: `
+CREATE SINK CONNECTOR a WITH ('connector.class'='JdbcSinkConnector', 'topics'='team-a, other-a');
+CREATE SOURCE CONNECTOR b WITH ('connector.class'='JdbcSourceConnector', 'topic.prefix'='team-');
+CREATE SOURCE CONNECTOR c WITH ('connector.class'='DatagenConnector', 'kafka.topic'='team-c');
+CREATE SOURCE CONNECTOR d WITH ('connector.class'='DebeziumConnector');`,
+			want: []string{
+				"policy test: a uses topic other-a which doesn't match team-.*",
+				"policy test: b names its topics by topic.prefix which can't be checked against team-.*",
+				"policy test: d doesn't list its topics in topics, topic, kafka.topic so they can't be checked against team-.*",
+			},
+		},
+		{
+			name: "connector topic creation",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{MaxPartitions: 6, MaxReplicas: 3},
+			sql: `
+CREATE SOURCE CONNECTOR a WITH ('connector.class'='JdbcSourceConnector', 'topic.creation.default.partitions'='12',
+  'topic.creation.default.replication.factor'='3');`,
+			want: []string{"policy test: a creates topics with 12 partitions, at most 6 are allowed"},
+		},
+		{
+			name: "connector value formats",
+			spec: ksqloperatorv1alpha1.KSQLPolicySpec{AllowedValueFormats: []string{"AVRO"}},
+			sql: `
+CREATE SOURCE CONNECTOR a WITH ('connector.class'='JdbcSourceConnector', 'value.converter'='io.confluent.connect.avro.AvroConverter');
+CREATE SOURCE CONNECTOR b WITH ('connector.class'='JdbcSourceConnector', 'value.converter'='org.apache.kafka.connect.json.JsonConverter');
+CREATE SOURCE CONNECTOR c WITH ('connector.class'='JdbcSourceConnector');
+CREATE SOURCE CONNECTOR d WITH ('connector.class'='JdbcSourceConnector', 'value.converter'='com.example.CustomConverter');
+CREATE SINK CONNECTOR e WITH ('connector.class'='JdbcSinkConnector', 'topics'='a');`,
+			want: []string{
+				"policy test: b uses VALUE_FORMAT JSON which isn't one of AVRO",
+				"policy test: c doesn't set value.converter so its VALUE_FORMAT can't be checked",
+				"policy test: d uses value.converter com.example.CustomConverter which doesn't write one of AVRO",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := ksqlparser.Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			policy := &ksqloperatorv1alpha1.KSQLPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: tt.spec}
+			got := policyViolations([]*ksqloperatorv1alpha1.KSQLPolicy{policy}, tt.namespace, stmts)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("policyViolations() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
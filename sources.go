@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// missingKey is a key of a ConfigMap or Secret which doesn't exist and isn't optional, it may yet be created
+type missingKey struct {
+	kind string
+	name string
+	key  string
+}
+
+func (e *missingKey) Error() string {
+	return fmt.Sprintf("key %s of %s %s not found", e.key, e.kind, e.name)
+}
+
+// statement is the ksql of managedKSQL, its statement followed by each fragment of statementFrom in order.
+// Fragments taken from an optional ConfigMap or Secret key which doesn't exist are left out.
+func (c *Controller) statement(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) (string, error) {
+	fragments := []string{managedKSQL.Statement}
+	for i, source := range managedKSQL.StatementFrom {
+		var fragment string
+		var found bool
+		var err error
+		switch {
+		case source.ConfigMapKeyRef != nil:
+			ref := source.ConfigMapKeyRef
+			fragment, found, err = c.configMapKey(managedKSQL.Namespace, ref.Name, ref.Key)
+			if err == nil && !found && (ref.Optional == nil || !*ref.Optional) {
+				err = &missingKey{kind: "ConfigMap", name: ref.Name, key: ref.Key}
+			}
+		case source.SecretKeyRef != nil:
+			ref := source.SecretKeyRef
+			fragment, found, err = c.secretKey(managedKSQL.Namespace, ref.Name, ref.Key)
+			if err == nil && !found && (ref.Optional == nil || !*ref.Optional) {
+				err = &missingKey{kind: "Secret", name: ref.Name, key: ref.Key}
+			}
+		default:
+			fragment, found = source.Statement, true
+		}
+		if err != nil {
+			return "", fmt.Errorf("error getting statementFrom[%d]: %w", i, err)
+		}
+		if found {
+			fragments = append(fragments, fragment)
+		}
+	}
+	return strings.Join(fragments, "\n"), nil
+}
+
+// referencesConfigMap reports whether managedKSQL takes statements or variables from the ConfigMap name
+func referencesConfigMap(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, name string) bool {
+	for _, source := range managedKSQL.StatementFrom {
+		if source.ConfigMapKeyRef != nil && source.ConfigMapKeyRef.Name == name {
+			return true
+		}
+	}
+	for _, v := range managedKSQL.Variables {
+		if v.ValueFrom != nil && v.ValueFrom.ConfigMapKeyRef != nil && v.ValueFrom.ConfigMapKeyRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesSecret reports whether managedKSQL takes statements or variables from the Secret name
+func referencesSecret(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, name string) bool {
+	for _, source := range managedKSQL.StatementFrom {
+		if source.SecretKeyRef != nil && source.SecretKeyRef.Name == name {
+			return true
+		}
+	}
+	for _, v := range managedKSQL.Variables {
+		if v.ValueFrom != nil && v.ValueFrom.SecretKeyRef != nil && v.ValueFrom.SecretKeyRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueReferencing puts the ManagedKSQLs taking statements or variables from the ConfigMap or Secret obj onto the
+// work queue
+func (c *Controller) enqueueReferencing(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	var kind, namespace, name string
+	var references func(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, name string) bool
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		kind, namespace, name, references = "ConfigMap", o.Namespace, o.Name, referencesConfigMap
+	case *corev1.Secret:
+		kind, namespace, name, references = "Secret", o.Namespace, o.Name, referencesSecret
+	default:
+		return
+	}
+	managedKSQLs, err := c.managedKSQLLister.ManagedKSQLs(namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing resources: %v", err)
+		return
+	}
+	for _, managedKSQL := range managedKSQLs {
+		if references(managedKSQL, name) {
+			klog.V(4).Infof("%s %s/%s has changed", kind, namespace, name)
+			c.enqueueManagedKSQL(managedKSQL)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+	listers "ksql_operator/pkg/generated/listers/ksql_operator/v1alpha1"
+)
+
+func TestEnqueueReferencing(t *testing.T) {
+	managedKSQLs := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, m := range []*ksqloperatorv1alpha1.ManagedKSQL{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "from-config-map"},
+			StatementFrom: []ksqloperatorv1alpha1.StatementSource{{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "ksql"},
+				Key:                  "statement",
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "from-secret"},
+			StatementFrom: []ksqloperatorv1alpha1.StatementSource{{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "ksql"},
+				Key:                  "statement",
+			}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "variable-from-secret"},
+			Variables: map[string]ksqloperatorv1alpha1.Variable{"password": {ValueFrom: &ksqloperatorv1alpha1.VariableSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "ksql"},
+					Key:                  "password",
+				},
+			}}},
+		},
+	} {
+		if err := managedKSQLs.Add(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name string
+		obj  interface{}
+		want []string
+	}{
+		{
+			name: "config map",
+			obj:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ksql"}},
+			want: []string{"ns/from-config-map"},
+		},
+		{
+			name: "secret",
+			obj:  &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ksql"}},
+			want: []string{"ns/from-secret", "ns/variable-from-secret"},
+		},
+		{
+			name: "deleted secret",
+			obj: cache.DeletedFinalStateUnknown{
+				Key: "ns/ksql",
+				Obj: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ksql"}},
+			},
+			want: []string{"ns/from-secret", "ns/variable-from-secret"},
+		},
+		{
+			name: "other namespace",
+			obj:  &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "ksql"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{
+				managedKSQLLister: listers.NewManagedKSQLLister(managedKSQLs),
+				workqueue:         workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+			}
+			c.enqueueReferencing(tt.obj)
+			var got []string
+			for c.workqueue.Len() > 0 {
+				key, _ := c.workqueue.Get()
+				got = append(got, key.(string))
+			}
+			sort.Strings(got)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("enqueueReferencing() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
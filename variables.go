@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+// variables are the values of the variables of managedKSQL, those taken from an optional ConfigMap or Secret key
+// which doesn't exist are left out
+func (c *Controller) variables(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) (map[string]string, error) {
+	result := map[string]string{}
+	for name, v := range managedKSQL.Variables {
+		if v.ValueFrom == nil {
+			result[name] = v.Value
+			continue
+		}
+		var value string
+		var found bool
+		var err error
+		switch {
+		case v.ValueFrom.ConfigMapKeyRef != nil:
+			ref := v.ValueFrom.ConfigMapKeyRef
+			value, found, err = c.configMapKey(managedKSQL.Namespace, ref.Name, ref.Key)
+			if err == nil && !found && (ref.Optional == nil || !*ref.Optional) {
+				err = &missingKey{kind: "ConfigMap", name: ref.Name, key: ref.Key}
+			}
+		case v.ValueFrom.SecretKeyRef != nil:
+			ref := v.ValueFrom.SecretKeyRef
+			value, found, err = c.secretKey(managedKSQL.Namespace, ref.Name, ref.Key)
+			if err == nil && !found && (ref.Optional == nil || !*ref.Optional) {
+				err = &missingKey{kind: "Secret", name: ref.Name, key: ref.Key}
+			}
+		default:
+			err = fmt.Errorf("valueFrom has no configMapKeyRef or secretKeyRef")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error getting variable %s: %w", name, err)
+		}
+		if found {
+			result[name] = value
+		}
+	}
+	return result, nil
+}
+
+// secretVariables are the values of the variables of managedKSQL taken from a Secret keyed by their name
+func (c *Controller) secretVariables(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) (map[string]string, error) {
+	variables, err := c.variables(managedKSQL)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	for name, v := range managedKSQL.Variables {
+		if value, ok := variables[name]; ok && v.ValueFrom != nil && v.ValueFrom.SecretKeyRef != nil {
+			result[name] = value
+		}
+	}
+	return result, nil
+}
+
+// configMapKey returns the value of key in the ConfigMap name and whether it was found
+func (c *Controller) configMapKey(namespace, name, key string) (string, bool, error) {
+	cm, err := c.configMapLister.ConfigMaps(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if value, ok := cm.Data[key]; ok {
+		return value, true, nil
+	}
+	value, ok := cm.BinaryData[key]
+	return string(value), ok, nil
+}
+
+// secretKey returns the value of key in the Secret name and whether it was found
+func (c *Controller) secretKey(namespace, name, key string) (string, bool, error) {
+	secret, err := c.secretLister.Secrets(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := secret.Data[key]
+	return string(value), ok, nil
+}
+
+// substituteVariables returns statement with the variables of managedKSQL substituted
+func (c *Controller) substituteVariables(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL, statement string) (string, error) {
+	variables, err := c.variables(managedKSQL)
+	if err != nil {
+		return "", err
+	}
+	return ksqlparser.Substitute(statement, variables)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+const (
+	// validatePath is where the validating admission webhook is served
+	validatePath = "/validate-managedksql"
+	// mutatePath is where the mutating admission webhook is served
+	mutatePath = "/mutate-managedksql"
+	// webhookShutdownTimeout is how long requests in flight are given to finish when stopping
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+// webhook serves the admission webhooks for ManagedKSQLs
+type webhook struct {
+	controller *Controller
+	// validatePolicies rejects ManagedKSQLs which break a KSQLPolicy
+	validatePolicies bool
+}
+
+// Serve serves the webhooks over TLS on addr until stopCh is closed.
+// The certificate is loaded again whenever certFile changes so that rotated certificates are picked up.
+func (wh *webhook) Serve(addr, certFile, keyFile string, stopCh <-chan struct{}) error {
+	certs := &certLoader{certFile: certFile, keyFile: keyFile}
+	if _, err := certs.GetCertificate(nil); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(validatePath, wh.handleValidate)
+	mux.HandleFunc(mutatePath, wh.handleMutate)
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   mux,
+		TLSConfig: &tls.Config{GetCertificate: certs.GetCertificate},
+	}
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			klog.Errorf("error shutting down webhook server: %v", err)
+		}
+	}()
+
+	klog.Infof("Serving webhooks on %s", addr)
+	if err := server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (wh *webhook) handleValidate(w http.ResponseWriter, r *http.Request) {
+	wh.serveReview(w, r, func(managedKSQL, old *ksqloperatorv1alpha1.ManagedKSQL, response *admissionv1.AdmissionResponse) {
+		if old != nil && specUnchanged(old, managedKSQL) {
+			// such as approving a plan, what was admitted before still is
+			return
+		}
+		warnings, err := wh.validate(managedKSQL)
+		response.Warnings = warnings
+		if err != nil {
+			klog.V(4).Infof("rejecting %s/%s: %v", managedKSQL.Namespace, managedKSQL.Name, err)
+			response.Allowed = false
+			response.Result = &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+				Reason:  metav1.StatusReasonInvalid,
+				Code:    http.StatusUnprocessableEntity,
+			}
+		}
+	})
+}
+
+func (wh *webhook) handleMutate(w http.ResponseWriter, r *http.Request) {
+	wh.serveReview(w, r, func(managedKSQL, old *ksqloperatorv1alpha1.ManagedKSQL, response *admissionv1.AdmissionResponse) {
+		patch, err := wh.mutate(managedKSQL, old)
+		if err != nil {
+			// leave it to the validating webhook and the controller to report
+			klog.V(4).Infof("not mutating %s/%s: %v", managedKSQL.Namespace, managedKSQL.Name, err)
+			return
+		}
+		if len(patch) == 0 {
+			return
+		}
+		if response.Patch, err = json.Marshal(patch); err != nil {
+			klog.Errorf("error encoding patch: %v", err)
+			return
+		}
+		patchType := admissionv1.PatchTypeJSONPatch
+		response.PatchType = &patchType
+	})
+}
+
+// serveReview decodes the ManagedKSQL of an admission review, and the one it replaces when it's an update, and writes
+// the response filled in by review
+func (wh *webhook) serveReview(w http.ResponseWriter, r *http.Request,
+	review func(managedKSQL, old *ksqloperatorv1alpha1.ManagedKSQL, response *admissionv1.AdmissionResponse)) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error reading request: %v", err), http.StatusBadRequest)
+		return
+	}
+	admissionReview := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, &admissionReview); err != nil || admissionReview.Request == nil {
+		http.Error(w, fmt.Sprintf("error decoding admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := &admissionv1.AdmissionResponse{UID: admissionReview.Request.UID, Allowed: true}
+	managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{}
+	var old *ksqloperatorv1alpha1.ManagedKSQL
+	err = json.Unmarshal(admissionReview.Request.Object.Raw, managedKSQL)
+	if err == nil && len(admissionReview.Request.OldObject.Raw) > 0 {
+		old = &ksqloperatorv1alpha1.ManagedKSQL{}
+		err = json.Unmarshal(admissionReview.Request.OldObject.Raw, old)
+	}
+	if err != nil {
+		response.Allowed = false
+		response.Result = &metav1.Status{Message: fmt.Sprintf("error decoding ManagedKSQL: %v", err)}
+	} else {
+		if managedKSQL.Namespace == "" {
+			managedKSQL.Namespace = admissionReview.Request.Namespace
+		}
+		review(managedKSQL, old, response)
+	}
+
+	admissionReview.Response = response
+	admissionReview.Request = nil
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(admissionReview); err != nil {
+		klog.Errorf("error writing admission review: %v", err)
+	}
+}
+
+// validate checks the statement of managedKSQL parses, that it can be put in dependency order and, when
+// validatePolicies is set, that it meets every KSQLPolicy. Sources which aren't declared are allowed as they may be
+// created later. So may a ConfigMap or Secret it references, it's returned as a warning and the statement isn't
+// checked.
+func (wh *webhook) validate(managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) ([]string, error) {
+	stmts, _, _, err := wh.controller.parseStatement(managedKSQL)
+	var missing *missingKey
+	if errors.As(err, &missing) {
+		return []string{fmt.Sprintf("the statement isn't checked until it can be read: %v", err)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ksqlparser.BuildDependencyGraph(stmts, func(string) (bool, error) { return true, nil }); err != nil {
+		return nil, err
+	}
+	if !wh.validatePolicies {
+		return nil, nil
+	}
+	policies, err := wh.controller.ksqlPolicyLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing policies: %v", err)
+	}
+	if violations := policyViolations(policies, managedKSQL.Namespace, stmts); len(violations) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(violations, "\n"))
+	}
+	return nil, nil
+}
+
+// specUnchanged reports whether the update of old to managedKSQL only changes its metadata or status
+func specUnchanged(old, managedKSQL *ksqloperatorv1alpha1.ManagedKSQL) bool {
+	o, m := old.DeepCopy(), managedKSQL.DeepCopy()
+	o.ObjectMeta, m.ObjectMeta = metav1.ObjectMeta{}, metav1.ObjectMeta{}
+	o.Status, m.Status = ksqloperatorv1alpha1.ManagedKSQLStatus{}, ksqloperatorv1alpha1.ManagedKSQLStatus{}
+	return equality.Semantic.DeepEqual(o, m)
+}
+
+// certLoader loads a certificate and key from files, loading them again when the certificate file changes
+type certLoader struct {
+	certFile string
+	keyFile  string
+
+	lock    sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (l *certLoader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	info, err := os.Stat(l.certFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading certificate: %v", err)
+	}
+	if l.cert != nil && !info.ModTime().After(l.modTime) {
+		return l.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading certificate: %v", err)
+	}
+	l.cert, l.modTime = &cert, info.ModTime()
+	return l.cert, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"ksql_operator/ksqlparser"
+	ksqloperatorv1alpha1 "ksql_operator/pkg/apis/ksql_operator/v1alpha1"
+)
+
+func TestSpecUnchanged(t *testing.T) {
+	old := &ksqloperatorv1alpha1.ManagedKSQL{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", ResourceVersion: "1"},
+		Statement:  "CREATE STREAM a AS SELECT * FROM b EMIT CHANGES;",
+	}
+	tests := []struct {
+		name   string
+		update func(m *ksqloperatorv1alpha1.ManagedKSQL)
+		want   bool
+	}{
+		{
+			name: "annotation",
+			update: func(m *ksqloperatorv1alpha1.ManagedKSQL) {
+				m.Annotations = map[string]string{approvedPlanAnnotation: "hash"}
+				m.ResourceVersion = "2"
+			},
+			want: true,
+		},
+		{
+			name:   "status",
+			update: func(m *ksqloperatorv1alpha1.ManagedKSQL) { m.Status.Applied = ksqloperatorv1alpha1.StatusApplied },
+			want:   true,
+		},
+		{
+			name: "statement",
+			update: func(m *ksqloperatorv1alpha1.ManagedKSQL) {
+				m.Statement = "CREATE STREAM a AS SELECT * FROM c EMIT CHANGES;"
+			},
+		},
+		{
+			name:   "dry run",
+			update: func(m *ksqloperatorv1alpha1.ManagedKSQL) { m.DryRun = true },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := old.DeepCopy()
+			tt.update(updated)
+			if got := specUnchanged(old, updated); got != tt.want {
+				t.Errorf("specUnchanged() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMissingKey(t *testing.T) {
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := configMaps.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ksql"},
+		Data:       map[string]string{"broken": "CREATE STREAM a AS SELECT FROM;"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	wh := &webhook{controller: &Controller{
+		configMapLister: corelisters.NewConfigMapLister(configMaps),
+		namespaceLister: corelisters.NewNamespaceLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+	}}
+	tests := []struct {
+		name         string
+		key          string
+		wantWarnings bool
+		wantErr      bool
+	}{
+		{name: "missing", key: "missing", wantWarnings: true},
+		{name: "broken", key: "broken", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			managedKSQL := &ksqloperatorv1alpha1.ManagedKSQL{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a"},
+				StatementFrom: []ksqloperatorv1alpha1.StatementSource{{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "ksql"},
+					Key:                  tt.key,
+				}}},
+			}
+			warnings, err := wh.validate(managedKSQL)
+			if (len(warnings) > 0) != tt.wantWarnings {
+				t.Errorf("validate() warnings = %v, want warnings %v", warnings, tt.wantWarnings)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMutateDefaults(t *testing.T) {
+	namespaces := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := namespaces.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ns",
+		Annotations: map[string]string{"mgazza.github.com/ksql-default-partitions": "6"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	wh := &webhook{controller: &Controller{namespaceLister: corelisters.NewNamespaceLister(namespaces)}}
+	running := &ksqloperatorv1alpha1.ManagedKSQL{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a"},
+		Statement:  ksqlparser.Format(mustParse(t, "CREATE STREAM a AS SELECT * FROM b EMIT CHANGES;"), ksqlparser.DefaultFormatOptions),
+	}
+	tests := []struct {
+		name          string
+		old           *ksqloperatorv1alpha1.ManagedKSQL
+		update        func(m *ksqloperatorv1alpha1.ManagedKSQL)
+		wantStatement bool
+	}{
+		{name: "create", wantStatement: true},
+		{
+			name:   "metadata",
+			old:    running,
+			update: func(m *ksqloperatorv1alpha1.ManagedKSQL) { m.Labels = map[string]string{"team": "a"} },
+		},
+		{
+			name: "statement",
+			old:  running,
+			update: func(m *ksqloperatorv1alpha1.ManagedKSQL) {
+				m.Statement = "CREATE STREAM a AS SELECT * FROM c EMIT CHANGES;"
+			},
+			wantStatement: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			managedKSQL := running.DeepCopy()
+			if tt.update != nil {
+				tt.update(managedKSQL)
+			}
+			patch, err := wh.mutate(managedKSQL, tt.old)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var statement string
+			for _, op := range patch {
+				if op.Path == "/statement" {
+					statement = op.Value.(string)
+				}
+			}
+			if gotStatement := strings.Contains(statement, "PARTITIONS"); gotStatement != tt.wantStatement {
+				t.Errorf("mutate() got statement %q, want defaults set %v", statement, tt.wantStatement)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, sql string) []ksqlparser.Stmt {
+	stmts, err := ksqlparser.Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stmts
+}